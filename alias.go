@@ -0,0 +1,154 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"sync"
+)
+
+// Logger is used to report the use of deprecated keys.
+// *log.Logger implements it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// Alias maps a deprecated key to the key which replaces it
+type Alias struct {
+	OldSection string
+	OldKey     string
+	NewSection string
+	NewKey     string
+}
+
+type aliases struct {
+	byOld map[lineKey]Alias
+	byNew map[lineKey][]Alias
+	list  []Alias // In registration order
+
+	logger Logger
+
+	mu     sync.Mutex
+	warned map[Alias]bool
+}
+
+// AddAlias registers the deprecated key oldKey in oldSection as an alias
+// of newKey in newSection.
+//
+// Looking up the new key with SectionGet or the typed getters finds the
+// value stored under the old key if the new key does not exist, and
+// looking up the old key finds the value of the new key. Either way a
+// deprecation warning is logged once per alias, if a Logger is set by
+// SetLogger. See Migrate.
+func (ini *INI) AddAlias(oldSection, oldKey, newSection, newKey string) {
+	a := &ini.aliases
+	if a.byOld == nil {
+		a.byOld = make(map[lineKey]Alias)
+		a.byNew = make(map[lineKey][]Alias)
+	}
+
+	alias := Alias{oldSection, oldKey, newSection, newKey}
+	old, new := ini.aliasKeys(alias)
+	a.byOld[old] = alias
+	a.byNew[new] = append(a.byNew[new], alias)
+	a.list = append(a.list, alias)
+}
+
+// SetLogger sets the Logger reporting the use of deprecated keys, e.g.
+// log.Default(). Nothing is logged until a Logger is set, and nil
+// disables the warnings again.
+func (ini *INI) SetLogger(l Logger) {
+	ini.aliases.logger = l
+}
+
+// Migrate moves the value of every deprecated key which exists to its new
+// key, so that Write writes the new names. If both keys exist the value of
+// the new key is kept. A section which becomes empty is deleted.
+// It returns the aliases which have been migrated.
+func (ini *INI) Migrate() []Alias {
+	var migrated []Alias
+	for _, alias := range ini.aliases.list {
+		old, new := ini.aliasKeys(alias)
+		kv, ok := ini.sections[old.section]
+		if !ok {
+			continue
+		}
+		value, ok := kv[old.key]
+		if !ok {
+			continue
+		}
+
+		if _, exists := ini.sections[new.section][new.key]; !exists {
+			if alias.OldSection == alias.NewSection {
+				ini.RenameKey(alias.OldSection, alias.OldKey, alias.NewKey)
+			} else {
+				ini.SectionSet(alias.NewSection, alias.NewKey, value)
+			}
+		}
+		ini.Delete(alias.OldSection, alias.OldKey)
+		if len(kv) == 0 && old.section != DefaultSection {
+			ini.DeleteSection(alias.OldSection)
+		}
+		migrated = append(migrated, alias)
+	}
+	return migrated
+}
+
+// resolveAlias looks up the key in section through the aliases
+func (ini *INI) resolveAlias(section, key string) (string, bool) {
+	value, alias, ok := ini.lookupAlias(section, key)
+	if ok {
+		ini.aliases.warn(alias)
+	}
+	return value, ok
+}
+
+// lookupAlias looks up the key in section through the aliases, and returns
+// the alias used
+func (ini *INI) lookupAlias(section, key string) (string, Alias, bool) {
+	a := &ini.aliases
+	for _, alias := range a.byNew[lineKey{section, key}] {
+		old, _ := ini.aliasKeys(alias)
+		if value, ok := ini.sections[old.section][old.key]; ok {
+			return value, alias, true
+		}
+	}
+
+	if alias, ok := a.byOld[lineKey{section, key}]; ok {
+		_, new := ini.aliasKeys(alias)
+		if value, ok := ini.sections[new.section][new.key]; ok {
+			return value, alias, true
+		}
+	}
+	return "", Alias{}, false
+}
+
+// aliasKeys returns the names under which the old and the new key of
+// alias are stored
+func (ini *INI) aliasKeys(alias Alias) (old, new lineKey) {
+	old = lineKey{ini.sectionName(alias.OldSection), ini.keyName(alias.OldKey)}
+	new = lineKey{ini.sectionName(alias.NewSection), ini.keyName(alias.NewKey)}
+	return old, new
+}
+
+// warn logs the use of the deprecated alias once
+func (a *aliases) warn(alias Alias) {
+	logger := a.logger
+	if logger == nil {
+		return
+	}
+
+	a.mu.Lock()
+	if a.warned == nil {
+		a.warned = make(map[Alias]bool)
+	}
+	warned := a.warned[alias]
+	a.warned[alias] = true
+	a.mu.Unlock()
+
+	if !warned {
+		logger.Printf("goini: [%s] %s is deprecated, use [%s] %s instead",
+			alias.OldSection, alias.OldKey, alias.NewSection, alias.NewKey)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+type testLogger struct {
+	lines []string
+}
+
+func (l *testLogger) Printf(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func newAliasTestINI(t *testing.T) (*INI, *testLogger) {
+	ini := parseTestINI(t, "retlimit=50\nb=1\n[old]\nport=80\n[server]\nname=x\ntimeout=1s\n", parseSections)
+
+	logger := &testLogger{}
+	ini.SetLogger(logger)
+	ini.AddAlias("", "retlimit", "", "ret_limit")
+	ini.AddAlias("old", "port", "server", "port")
+	ini.AddAlias("server", "timeout", "server", "read_timeout")
+	return ini, logger
+}
+
+func TestAlias(t *testing.T) {
+	ini, logger := newAliasTestINI(t)
+
+	i, ok := ini.GetInt("ret_limit")
+	assert.Equal(t, i, 50)
+	assert.Equal(t, ok, true)
+	i, ok = ini.SectionGetInt("server", "port")
+	assert.Equal(t, i, 80)
+	assert.Equal(t, ok, true)
+	i, ok = ini.SectionGetInt("server", "port") // warn only once
+	assert.Equal(t, logger.lines, []string{
+		"goini: [] retlimit is deprecated, use [] ret_limit instead",
+		"goini: [old] port is deprecated, use [server] port instead",
+	})
+
+	// The new key wins
+	ini.SectionSet("server", "port", "8080")
+	v, _ := ini.SectionGet("server", "port")
+	assert.Equal(t, v, "8080")
+
+	// The old name finds the new key
+	ini.Delete("", "retlimit")
+	ini.Set("ret_limit", "60")
+	v, ok = ini.Get("retlimit")
+	assert.Equal(t, v, "60")
+	assert.Equal(t, ok, true)
+
+	_, ok = ini.Get("nonexist")
+	assert.Equal(t, ok, false)
+
+	ini.SetLogger(nil)
+	v, _ = ini.SectionGet("server", "read_timeout")
+	assert.Equal(t, v, "1s")
+	assert.Equal(t, len(logger.lines), 2)
+}
+
+func TestAliasWithoutLogger(t *testing.T) {
+	var buf bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&buf)
+
+	ini := New()
+	ini.AddAlias("", "retlimit", "", "ret_limit")
+	ini.Set("retlimit", "50")
+	v, _ := ini.Get("ret_limit")
+	assert.Equal(t, v, "50")
+	assert.Equal(t, buf.String(), "")
+
+	// The use which was not logged is logged once a Logger is set
+	logger := &testLogger{}
+	ini.SetLogger(logger)
+	ini.Get("ret_limit")
+	assert.Equal(t, len(logger.lines), 1)
+}
+
+func TestMigrate(t *testing.T) {
+	ini, logger := newAliasTestINI(t)
+	ini.SectionSet("server", "port", "8080")
+
+	migrated := ini.Migrate()
+	assert.Equal(t, len(migrated), 3)
+	assert.Equal(t, migrated[1], Alias{"old", "port", "server", "port"})
+	assert.Equal(t, writeString(t, ini), "ret_limit=50\nb=1\n[server]\nname=x\nread_timeout=1s\nport=8080\n")
+	assert.Equal(t, len(ini.Migrate()), 0)
+	assert.Equal(t, len(logger.lines), 0)
+
+	c := ini.Clone()
+	c.Set("retlimit", "1")
+	v, _ := c.Get("ret_limit")
+	assert.Equal(t, v, "50")
+	c.Delete("", "ret_limit")
+	v, _ = c.Get("ret_limit")
+	assert.Equal(t, v, "1")
+	assert.Equal(t, len(logger.lines), 1)
+}
@@ -0,0 +1,123 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"strings"
+)
+
+// EqualOption changes how Equal compares two INI documents
+type EqualOption int
+
+const (
+	// IgnoreOrder ignores the order of the sections and the keys
+	IgnoreOrder EqualOption = 1 << iota
+
+	// IgnoreWhitespace ignores leading and trailing whitespace of the keys
+	// and the values, and treats every run of inner whitespace as one space
+	IgnoreWhitespace
+
+	// IgnoreComments ignores the inline comments of the keys
+	IgnoreComments
+)
+
+// Clone returns a deep copy of this INI, including its parser settings.
+// The aliases are copied too, but not the subscriptions.
+func (ini *INI) Clone() *INI {
+	c := ini.copyData()
+	ini.copySettings(c)
+	c.filename = ini.filename
+	c.inherited = ini.inherited
+	for _, a := range ini.aliases.list {
+		c.AddAlias(a.OldSection, a.OldKey, a.NewSection, a.NewKey)
+	}
+	c.aliases.logger = ini.aliases.logger
+	return c
+}
+
+// copySettings copies the parser settings of this INI to c
+func (ini *INI) copySettings(c *INI) {
+	if ini.dialect != nil {
+		c.SetDialect(*ini.dialect)
+	}
+	c.foldSections = ini.foldSections
+	c.foldKeys = ini.foldKeys
+	c.subsections = ini.subsections
+	c.lineSep = ini.lineSep
+	c.kvSep = ini.kvSep
+	c.parseSection = ini.parseSection
+	c.skipCommits = ini.skipCommits
+	c.trimQuotes = ini.trimQuotes
+	c.inlineComments = ini.inlineComments
+}
+
+// Equal reports whether this INI and other hold the same sections and
+// key/value pairs in the same order, with the same inline comments.
+// The parser settings are not compared. An empty default section is the same as no default section.
+func (ini *INI) Equal(other *INI, opts ...EqualOption) bool {
+	var opt EqualOption
+	for _, o := range opts {
+		opt |= o
+	}
+
+	norm := func(s string) string {
+		if opt&IgnoreWhitespace != 0 {
+			return strings.Join(strings.Fields(s), " ")
+		}
+		return s
+	}
+
+	a, b := ini.equalSectionList(), other.equalSectionList()
+	if len(a) != len(b) {
+		return false
+	}
+	if opt&IgnoreOrder == 0 {
+		for i := range a {
+			if a[i] != b[i] {
+				return false
+			}
+		}
+	}
+
+	for _, section := range a {
+		if _, ok := other.sections[section]; !ok {
+			return false
+		}
+
+		akeys, bkeys := ini.keyList(section), other.keyList(section)
+		if len(akeys) != len(bkeys) {
+			return false
+		}
+
+		// Compare the normalized key/value pairs
+		bkv := make(map[string]string, len(bkeys))
+		for i, key := range bkeys {
+			if opt&IgnoreOrder == 0 && norm(key) != norm(akeys[i]) {
+				return false
+			}
+			bkv[norm(key)] = norm(other.sections[section][key])
+		}
+		for _, key := range akeys {
+			v, ok := bkv[norm(key)]
+			if !ok || v != norm(ini.sections[section][key]) {
+				return false
+			}
+			if opt&IgnoreComments == 0 &&
+				norm(ini.comments[lineKey{section, key}]) != norm(other.comments[lineKey{section, key}]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// equalSectionList returns the sections compared by Equal
+func (ini *INI) equalSectionList() []string {
+	names := ini.sectionList()
+	if len(names) > 0 && names[0] == DefaultSection && len(ini.sections[DefaultSection]) == 0 {
+		names = names[1:]
+	}
+	return names
+}
@@ -0,0 +1,83 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestClone(t *testing.T) {
+	raw := []byte("a:1||b:2||c:'3'")
+	ini := New()
+	ini.SetTrimQuotes(true)
+	err := ini.Parse(raw, "||", ":")
+	assert.Equal(t, nil, err)
+
+	c := ini.Clone()
+	assert.Equal(t, c.Equal(ini), true)
+	assert.Equal(t, writeString(t, c), "a:1||b:2||c:3||")
+
+	c.Set("a", "10")
+	c.SectionSet("sss", "x", "y")
+	v, _ := ini.Get("a")
+	assert.Equal(t, v, "1")
+	assert.Equal(t, ini.HasSection("sss"), false)
+	assert.Equal(t, c.Equal(ini), false)
+
+	err = c.Parse([]byte("d:'4'"), "||", ":")
+	assert.Equal(t, nil, err)
+	v, _ = c.Get("d")
+	assert.Equal(t, v, "4")
+}
+
+func TestCloneFile(t *testing.T) {
+	filename := filepath.Join(getTestDataDir(t), "ini_parser_testfile.ini")
+	ini := New()
+	err := ini.ParseFile(filename)
+	assert.Equal(t, nil, err)
+
+	c := ini.Clone()
+	assert.Equal(t, c.Equal(ini), true)
+	assert.Equal(t, writeString(t, c), writeString(t, ini))
+
+	err = c.Reload()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, c.Equal(ini), true)
+}
+
+func TestEqual(t *testing.T) {
+	a := New()
+	a.Set("x", "1")
+	a.SectionSet("sss", "a", "hello world")
+	a.SectionSet("sss", "b", "2")
+
+	b := New()
+	b.SectionSet("sss", "b", "2")
+	b.SectionSet("sss", "a", " hello   world ")
+	b.Set("x", "1")
+
+	assert.Equal(t, a.Equal(b), false)
+	assert.Equal(t, a.Equal(b, IgnoreOrder), false)
+	assert.Equal(t, a.Equal(b, IgnoreWhitespace), false)
+	assert.Equal(t, a.Equal(b, IgnoreOrder, IgnoreWhitespace), true)
+	assert.Equal(t, a.Equal(b, IgnoreOrder|IgnoreWhitespace), true)
+
+	b.SectionSet("ddd", "c", "3")
+	assert.Equal(t, a.Equal(b, IgnoreOrder|IgnoreWhitespace), false)
+
+	// An empty default section is the same as no default section
+	c := New()
+	err := c.Parse([]byte(""), "\n", "=")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, c.Equal(New()), true)
+	assert.Equal(t, New().Equal(c), true)
+	c.SectionSet("sss", "a", "1")
+	d := New()
+	d.SectionSet("ddd", "a", "1")
+	assert.Equal(t, c.Equal(d, IgnoreOrder), false)
+}
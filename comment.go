@@ -0,0 +1,149 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"bytes"
+	"strings"
+)
+
+// SetInlineComments sets whether to strip the inline comments after the
+// values when parsing, e.g. the value of "url = http://x ; primary" is
+// "http://x". See Dialect.InlineComments.
+func (ini *INI) SetInlineComments(v bool) {
+	ini.inlineComments = v
+	ini.cache = nil
+	if ini.dialect != nil {
+		ini.dialect.InlineComments = v
+	}
+}
+
+// InlineComment returns the inline comment of the key in section, including
+// its comment prefix, e.g. "; primary". It returns "" if there is none.
+func (ini *INI) InlineComment(section, key string) string {
+	return ini.comments[lineKey{ini.sectionName(section), ini.keyName(key)}]
+}
+
+// SetInlineComment sets the inline comment which Write writes after the
+// value of the key in section. The first comment prefix of the dialect is
+// prepended if the comment does not start with one, and an empty comment
+// removes the inline comment. It does nothing if the key does not exist.
+func (ini *INI) SetInlineComment(section, key, comment string) {
+	section, key = ini.sectionName(section), ini.keyName(key)
+	if _, ok := ini.sections[section][key]; !ok {
+		return
+	}
+	comment = strings.TrimSpace(comment)
+	prefixes := ini.parseDialect(ini.lineSep, ini.kvSep).inlineCommentPrefixes()
+	if comment != "" && !hasAnyPrefix([]byte(comment), prefixes) {
+		comment = prefixes[0] + " " + comment
+	}
+	ini.setComment(section, key, comment)
+}
+
+// setComment records the inline comment of a key
+func (ini *INI) setComment(section, key, comment string) {
+	if comment == "" {
+		delete(ini.comments, lineKey{section, key})
+		return
+	}
+	if ini.comments == nil {
+		ini.comments = make(map[lineKey]string)
+	}
+	ini.comments[lineKey{section, key}] = comment
+}
+
+// inlineCommentPrefixes returns the prefixes starting an inline comment,
+// which are the comment prefixes, or ";" and "#" if there is none
+func (d *Dialect) inlineCommentPrefixes() []string {
+	for _, prefix := range d.CommentPrefixes {
+		if prefix != "" {
+			return d.CommentPrefixes
+		}
+	}
+	return legacyComments
+}
+
+// splitComment splits the value v at the start of its inline comment.
+// A comment starts with a comment prefix at the beginning of v or after
+// whitespace, which is neither enclosed by the quotation marks around the
+// value nor escaped by a backslash. The escaped comment prefixes of an
+// unquoted value are unescaped.
+func (d *Dialect) splitComment(v []byte) (value, comment []byte) {
+	prefixes := d.inlineCommentPrefixes()
+	quotes := d.Quotes
+	if quotes == "" {
+		quotes = `"'`
+	}
+
+	start := quotedLen(v, quotes)
+	if start < 0 {
+		start = 0
+	}
+
+	value = v
+	for i := start; i < len(v); i++ {
+		if v[i] == '\\' {
+			i++
+			continue
+		}
+		if (i == 0 || isSpace(v[i-1])) && hasAnyPrefix(v[i:], prefixes) {
+			value, comment = bytes.TrimRight(v[:i], " \t"), v[i:]
+			break
+		}
+	}
+
+	if start == 0 {
+		value = unescapeComment(value, prefixes)
+	}
+	return value, bytes.TrimSpace(comment)
+}
+
+// unescapeComment removes the backslashes escaping comment prefixes in v
+func unescapeComment(v []byte, prefixes []string) []byte {
+	if bytes.IndexByte(v, '\\') < 0 {
+		return v
+	}
+	out := make([]byte, 0, len(v))
+	for i := 0; i < len(v); i++ {
+		if v[i] == '\\' && hasAnyPrefix(v[i+1:], prefixes) {
+			continue
+		}
+		out = append(out, v[i])
+	}
+	return out
+}
+
+// escapeComment escapes the comment prefixes in v which would start an
+// inline comment or be unescaped, so that v is parsed back unchanged
+func escapeComment(v string, prefixes []string) string {
+	var b strings.Builder
+	last := 0
+	for i := 0; i < len(v); i++ {
+		if (i == 0 || isSpace(v[i-1]) || v[i-1] == '\\') && hasAnyPrefix([]byte(v[i:]), prefixes) {
+			b.WriteString(v[last:i])
+			b.WriteByte('\\')
+			last = i
+		}
+	}
+	if last == 0 && b.Len() == 0 {
+		return v
+	}
+	b.WriteString(v[last:])
+	return b.String()
+}
+
+func hasAnyPrefix(s []byte, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if prefix != "" && bytes.HasPrefix(s, []byte(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
@@ -0,0 +1,136 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestInlineComments(t *testing.T) {
+	raw := []byte(`url = http://x ; primary
+color = \#fff # white
+path = a\;b \# c
+quoted = "a ; b" ; quoted
+single = 'x # y'
+empty = ; nothing
+plain=a;b#c
+`)
+	ini := New()
+	ini.SetInlineComments(true)
+	err := ini.Parse(raw, "\n", "=")
+	assert.Equal(t, nil, err)
+
+	tests := []struct {
+		key, value, comment string
+	}{
+		{"url", "http://x", "; primary"},
+		{"color", "#fff", "# white"},
+		{"path", "a;b # c", ""},
+		{"quoted", `"a ; b"`, "; quoted"},
+		{"single", `'x # y'`, ""},
+		{"empty", "", "; nothing"},
+		{"plain", "a;b#c", ""},
+	}
+	for _, test := range tests {
+		v, ok := ini.Get(test.key)
+		assert.Equal(t, ok, true)
+		assert.Equal(t, v, test.value)
+		assert.Equal(t, ini.InlineComment("", test.key), test.comment)
+	}
+}
+
+func TestInlineCommentsDisabled(t *testing.T) {
+	ini := New()
+	err := ini.Parse([]byte("url = http://x ; primary\n"), "\n", "=")
+	assert.Equal(t, nil, err)
+	v, _ := ini.Get("url")
+	assert.Equal(t, v, "http://x ; primary")
+	assert.Equal(t, ini.InlineComment("", "url"), "")
+}
+
+func TestInlineCommentsQuotes(t *testing.T) {
+	ini := New()
+	ini.SetInlineComments(true)
+	ini.SetTrimQuotes(true)
+	err := ini.Parse([]byte(`a = "x ; y" ; note`+"\n"), "\n", "=")
+	assert.Equal(t, nil, err)
+	v, _ := ini.Get("a")
+	assert.Equal(t, v, "x ; y")
+	assert.Equal(t, ini.InlineComment("", "a"), "; note")
+}
+
+func TestInlineCommentsDialect(t *testing.T) {
+	ini := New()
+	d := DefaultDialect
+	d.CommentPrefixes = []string{"//"}
+	d.InlineComments = true
+	ini.SetDialect(d)
+	err := ini.Parse([]byte("[s]\na = http://x // primary\nb = x ; y\n"), "", "")
+	assert.Equal(t, nil, err)
+	v, _ := ini.SectionGet("s", "a")
+	assert.Equal(t, v, "http://x")
+	assert.Equal(t, ini.InlineComment("s", "a"), "// primary")
+	v, _ = ini.SectionGet("s", "b")
+	assert.Equal(t, v, "x ; y")
+}
+
+func TestInlineCommentsWrite(t *testing.T) {
+	raw := "url=http://x ; primary\n[s]\npath=a \\; b\n"
+	ini := New()
+	ini.SetParseSection(true)
+	ini.SetInlineComments(true)
+	err := ini.Parse([]byte(raw), "\n", "=")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, writeString(t, ini), raw)
+
+	// Values which look like comments are escaped
+	ini.SectionSet("s", "color", "#fff")
+	ini.SetInlineComment("s", "color", "white")
+	ini.Set("url", "http://y") // Keeps its comment
+	assert.Equal(t, writeString(t, ini),
+		"url=http://y ; primary\n[s]\npath=a \\; b\ncolor=\\#fff ; white\n")
+
+	c := New()
+	c.SetParseSection(true)
+	c.SetInlineComments(true)
+	err = c.Parse([]byte(writeString(t, ini)), "\n", "=")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, c.Equal(ini), true)
+	v, _ := c.SectionGet("s", "color")
+	assert.Equal(t, v, "#fff")
+}
+
+func TestInlineCommentsEdit(t *testing.T) {
+	ini := New()
+	ini.SetParseSection(true)
+	ini.SetInlineComments(true)
+	err := ini.Parse([]byte("[s]\na=1 ; one\nb=2 # two\n"), "\n", "=")
+	assert.Equal(t, nil, err)
+
+	err = ini.RenameKey("s", "a", "c")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, ini.InlineComment("s", "c"), "; one")
+	err = ini.CopySection("s", "t")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, ini.InlineComment("t", "b"), "# two")
+	err = ini.RenameSection("t", "u")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, ini.InlineComment("u", "b"), "# two")
+
+	c := ini.Clone()
+	assert.Equal(t, c.Equal(ini), true)
+	c.SetInlineComment("s", "b", "")
+	assert.Equal(t, c.InlineComment("s", "b"), "")
+	assert.Equal(t, c.Equal(ini), false)
+	assert.Equal(t, c.Equal(ini, IgnoreComments), true)
+
+	ini.Delete("s", "c")
+	assert.Equal(t, ini.InlineComment("s", "c"), "")
+	ini.DeleteSection("u")
+	assert.Equal(t, ini.InlineComment("u", "b"), "")
+	assert.Equal(t, writeString(t, ini), "[s]\nb=2 # two\n")
+}
@@ -0,0 +1,317 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"bytes"
+)
+
+// Dialect describes the syntax of an INI flavor. See SetDialect.
+type Dialect struct {
+	// LineSeparator separates the lines. Empty means DefaultLineSeparator.
+	LineSeparator string
+
+	// KeyValueSeparators are the separators allowed between a key and its
+	// value. The one which appears first in a line is used, and Write uses
+	// the first one. Empty means DefaultKeyValueSeparator.
+	KeyValueSeparators []string
+
+	// CommentPrefixes are the prefixes of comment lines, which are skipped
+	CommentPrefixes []string
+
+	// SectionStart and SectionEnd enclose the name of a section.
+	// Sections are not parsed if SectionStart is empty.
+	SectionStart string
+	SectionEnd   string
+
+	// CaseInsensitiveSections and CaseInsensitiveKeys make the names of the
+	// sections and the keys case insensitive. See SetCaseInsensitive.
+	CaseInsensitiveSections bool
+	CaseInsensitiveKeys     bool
+
+	// Subsections stores the git config style sections like
+	// [remote "origin"] under their dotted names like remote.origin, so
+	// that both names refer to the same section. The name of the
+	// subsection is case sensitive and may contain dots. See SetSubsections.
+	Subsections bool
+
+	// KeepValueSpace keeps the whitespace around the values, which is
+	// trimmed by default. The whitespace around the keys is always trimmed.
+	KeepValueSpace bool
+
+	// Quotes are the quotation marks which may enclose a value. A value
+	// enclosed by a matching pair of quotation marks is unquoted, and the
+	// escape sequences of a double quoted value are replaced. If Quotes
+	// contains the double quotation mark, Write quotes the values which
+	// could not be parsed back otherwise.
+	Quotes string
+
+	// InlineComments strips the comments which follow the values. An inline
+	// comment starts with one of CommentPrefixes (";" or "#" if there is
+	// none) after whitespace, out of the quotation marks around the value.
+	// A backslash escapes a comment prefix. The comments are kept for Write,
+	// see InlineComment.
+	InlineComments bool
+
+	// LineContinuation joins a line ending with an unescaped backslash and
+	// the next line, of which the leading whitespace is skipped. A comment
+	// line is never continued.
+	LineContinuation bool
+
+	// Escapes replaces the escape sequences of the Java .properties files
+	// in the keys and the values : \t, \n, \r, \f, \uXXXX, and a backslash
+	// followed by any other character, which stands for the character. So
+	// the separators are escaped by a backslash in the keys. Write escapes
+	// the special characters and every character which is not printable
+	// ASCII. The keys and the values which are not valid UTF-8 are decoded
+	// as ISO-8859-1. As in Java, the trailing whitespace of the values is
+	// kept.
+	Escapes bool
+
+	// AllowNoValue accepts the lines holding only a key, of which the value
+	// is empty. Such a line is an error by default.
+	AllowNoValue bool
+
+	// ExportPrefix skips the shell keyword "export" before the keys
+	ExportPrefix bool
+
+	// Expand replaces the references ${NAME} and $NAME in the values which
+	// are not single quoted by the value of the key NAME defined before in
+	// the same section, or else by the environment variable NAME, or else
+	// by nothing. A backslash escapes the dollar sign, which Write escapes.
+	Expand bool
+
+	// hashComments makes the lines starting with # comments, as they are
+	// when no dialect is set, even if the comments are not skipped
+	hashComments bool
+}
+
+// The predefined dialects of some common INI flavors
+var (
+	// DefaultDialect is the dialect used by ParseFile if no dialect is set
+	DefaultDialect = Dialect{
+		LineSeparator:      DefaultLineSeparator,
+		KeyValueSeparators: []string{DefaultKeyValueSeparator},
+		CommentPrefixes:    []string{";", "#"},
+		SectionStart:       "[",
+		SectionEnd:         "]",
+	}
+
+	// WindowsDialect is the dialect of the Windows INI files (GetPrivateProfileString)
+	WindowsDialect = Dialect{
+		LineSeparator:           "\r\n",
+		KeyValueSeparators:      []string{"="},
+		CommentPrefixes:         []string{";"},
+		SectionStart:            "[",
+		SectionEnd:              "]",
+		CaseInsensitiveSections: true,
+		CaseInsensitiveKeys:     true,
+		Quotes:                  `"`,
+	}
+
+	// PHPDialect is the dialect of the php.ini files (parse_ini_file)
+	PHPDialect = Dialect{
+		LineSeparator:      DefaultLineSeparator,
+		KeyValueSeparators: []string{"="},
+		CommentPrefixes:    []string{";", "#"},
+		SectionStart:       "[",
+		SectionEnd:         "]",
+		Quotes:             `"'`,
+	}
+
+	// PythonDialect is the dialect of the Python configparser files
+	PythonDialect = Dialect{
+		LineSeparator:       DefaultLineSeparator,
+		KeyValueSeparators:  []string{"=", ":"},
+		CommentPrefixes:     []string{";", "#"},
+		SectionStart:        "[",
+		SectionEnd:          "]",
+		CaseInsensitiveKeys: true,
+	}
+
+	// GitConfigDialect is the dialect of the git config files
+	GitConfigDialect = Dialect{
+		LineSeparator:           DefaultLineSeparator,
+		KeyValueSeparators:      []string{"="},
+		CommentPrefixes:         []string{";", "#"},
+		SectionStart:            "[",
+		SectionEnd:              "]",
+		CaseInsensitiveSections: true,
+		CaseInsensitiveKeys:     true,
+		Subsections:             true,
+		Quotes:                  `"`,
+	}
+
+	// PropertiesDialect is the dialect of the Java .properties files, which
+	// have no sections. A whitespace separator may be followed by = or :.
+	PropertiesDialect = Dialect{
+		LineSeparator:      DefaultLineSeparator,
+		KeyValueSeparators: []string{"=", ":", " ", "\t", "\f"},
+		CommentPrefixes:    []string{"#", "!"},
+		LineContinuation:   true,
+		Escapes:            true,
+		AllowNoValue:       true,
+	}
+
+	// DotenvDialect is the dialect of the .env files, which have no
+	// sections. A single quoted value is taken literally, while the escape
+	// sequences and the references of a double quoted value are replaced.
+	DotenvDialect = Dialect{
+		LineSeparator:      DefaultLineSeparator,
+		KeyValueSeparators: []string{"="},
+		CommentPrefixes:    []string{"#"},
+		Quotes:             `"'`,
+		InlineComments:     true,
+		ExportPrefix:       true,
+		Expand:             true,
+	}
+)
+
+// legacyComments are the comment prefixes enabled by SetSkipCommits
+var legacyComments = []string{";", "#"}
+
+// SetDialect sets the dialect used by ParseFile, Parse, ParseFrom and Write.
+// The settings changed by SetSkipCommits, SetParseSection and SetTrimQuotes
+// are replaced by the dialect, but the separators given to Parse and
+// ParseFrom still override the separators of the dialect if not empty.
+func (ini *INI) SetDialect(d Dialect) {
+	ini.dialect = newDialect(d)
+	ini.cache = nil
+	ini.foldSections = d.CaseInsensitiveSections
+	ini.foldKeys = d.CaseInsensitiveKeys
+	ini.subsections = d.Subsections
+	ini.inlineComments = d.InlineComments
+	ini.lineSep = ini.dialect.LineSeparator
+	ini.kvSep = ini.dialect.KeyValueSeparators[0]
+}
+
+// newDialect returns a copy of d with the default separators filled in
+func newDialect(d Dialect) *Dialect {
+	d.KeyValueSeparators = append([]string(nil), d.KeyValueSeparators...)
+	d.CommentPrefixes = append([]string(nil), d.CommentPrefixes...)
+	if d.LineSeparator == "" {
+		d.LineSeparator = DefaultLineSeparator
+	}
+	if len(d.KeyValueSeparators) == 0 {
+		d.KeyValueSeparators = []string{DefaultKeyValueSeparator}
+	}
+	return &d
+}
+
+// Dialect returns the dialect set by SetDialect, or the dialect equivalent
+// to the current settings if no dialect is set.
+func (ini *INI) Dialect() Dialect {
+	return *ini.parseDialect(ini.lineSep, ini.kvSep)
+}
+
+// parseDialect returns the dialect to parse the data separated by lineSep
+// and kvSep, which override the separators of the dialect if not empty.
+func (ini *INI) parseDialect(lineSep, kvSep string) *Dialect {
+	var d Dialect
+	if ini.dialect != nil {
+		d = *ini.dialect
+	} else {
+		d.KeyValueSeparators = []string{DefaultKeyValueSeparator}
+		d.hashComments = true
+		if ini.skipCommits {
+			d.CommentPrefixes = legacyComments
+		}
+		if ini.parseSection {
+			d.SectionStart, d.SectionEnd = "[", "]"
+		}
+		if ini.trimQuotes {
+			d.Quotes = `"'`
+		}
+		d.CaseInsensitiveSections = ini.foldSections
+		d.CaseInsensitiveKeys = ini.foldKeys
+		d.Subsections = ini.subsections
+		d.InlineComments = ini.inlineComments
+	}
+	if lineSep != "" {
+		d.LineSeparator = lineSep
+	}
+	if d.LineSeparator == "" {
+		d.LineSeparator = DefaultLineSeparator
+	}
+	if kvSep != "" {
+		d.KeyValueSeparators = []string{kvSep}
+	}
+	return &d
+}
+
+// isComment reports whether the trimmed line is a comment
+func (d *Dialect) isComment(line []byte) bool {
+	if d.hashComments && bytes.HasPrefix(line, []byte("#")) {
+		return true
+	}
+	for _, prefix := range d.CommentPrefixes {
+		if prefix != "" && bytes.HasPrefix(line, []byte(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// sectionHeader returns the name of the section if the trimmed line is a
+// section header
+func (d *Dialect) sectionHeader(line []byte) ([]byte, bool) {
+	if d.SectionStart == "" || len(line) < len(d.SectionStart)+len(d.SectionEnd) {
+		return nil, false
+	}
+	if !bytes.HasPrefix(line, []byte(d.SectionStart)) || !bytes.HasSuffix(line, []byte(d.SectionEnd)) {
+		return nil, false
+	}
+	return line[len(d.SectionStart) : len(line)-len(d.SectionEnd)], true
+}
+
+// splitKeyValue splits the line at the first key/value separator.
+// The longest separator wins if several ones start at the same position,
+// and a whitespace separator may be followed by another separator.
+func (d *Dialect) splitKeyValue(line []byte) (k, v []byte, ok bool) {
+	pos, size := d.indexSeparator(line)
+	if pos < 0 {
+		return nil, nil, false
+	}
+	k, v = line[:pos], line[pos+size:]
+	if isSpace(line[pos]) || line[pos] == '\f' {
+		rest := bytes.TrimLeft(v, " \t\f")
+		if i, n := d.indexSeparator(rest); i == 0 && !isSpace(rest[0]) && rest[0] != '\f' {
+			v = rest[n:]
+		}
+	}
+	return k, v, true
+}
+
+// indexSeparator returns the position and the size of the first key/value
+// separator in line, which is not escaped if the dialect has escapes.
+// The position is -1 if there is none.
+func (d *Dialect) indexSeparator(line []byte) (pos, size int) {
+	pos = -1
+	if d.Escapes {
+		for i := 0; i < len(line) && pos < 0; i++ {
+			if line[i] == '\\' {
+				i++
+				continue
+			}
+			for _, sep := range d.KeyValueSeparators {
+				if sep != "" && len(sep) > size && bytes.HasPrefix(line[i:], []byte(sep)) {
+					pos, size = i, len(sep)
+				}
+			}
+		}
+		return pos, size
+	}
+
+	for _, sep := range d.KeyValueSeparators {
+		if sep == "" {
+			continue
+		}
+		i := bytes.Index(line, []byte(sep))
+		if i >= 0 && (pos < 0 || i < pos || (i == pos && len(sep) > size)) {
+			pos, size = i, len(sep)
+		}
+	}
+	return pos, size
+}
@@ -0,0 +1,123 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func withDialect(d Dialect) func(ini *INI) {
+	return func(ini *INI) {
+		ini.SetDialect(d)
+	}
+}
+
+func TestDialect(t *testing.T) {
+	ini := parseTestINI(t, "! comment\n  // comment\na = 1\nb: 2\nc := 3\nd=x:y\n<Sec>\nKey = ' v '\n", withDialect(Dialect{
+		KeyValueSeparators:      []string{"=", ":", ":="},
+		CommentPrefixes:         []string{"!", "//"},
+		SectionStart:            "<",
+		SectionEnd:              ">",
+		CaseInsensitiveSections: true,
+		CaseInsensitiveKeys:     true,
+		Quotes:                  "'",
+	}))
+
+	v, _ := ini.Get("a")
+	assert.Equal(t, v, "1")
+	v, _ = ini.Get("b")
+	assert.Equal(t, v, "2")
+	v, _ = ini.Get("c")
+	assert.Equal(t, v, "3")
+	v, _ = ini.Get("d")
+	assert.Equal(t, v, "x:y")
+	v, ok := ini.SectionGet("sec", "key")
+	assert.Equal(t, v, " v ")
+	assert.Equal(t, ok, true)
+
+	assert.Equal(t, writeString(t, ini), "a=1\nb=2\nc=3\nd=x:y\n<Sec>\nKey= v \n")
+
+	// The separators given to Parse override the dialect
+	ini.Reset()
+	err := ini.Parse([]byte("a=1||b:2"), "||", ":")
+	assert.NotEqual(t, nil, err)
+	err = ini.Parse([]byte("a:1||b:2"), "||", ":")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, writeString(t, ini), "a:1||b:2||")
+}
+
+func TestDialectKeepValueSpace(t *testing.T) {
+	d := PythonDialect
+	d.KeepValueSpace = true
+	ini := parseTestINI(t, "  A =  x \n[s]\nb: y", withDialect(d))
+	v, _ := ini.Get("a")
+	assert.Equal(t, v, "  x ")
+	v, _ = ini.SectionGet("s", "b")
+	assert.Equal(t, v, " y")
+}
+
+func TestDialectFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "win.ini")
+	err := os.WriteFile(filename, []byte("; comment\r\n[Boot]\r\nShell=\"explorer.exe\"\r\n#x=1\r\n"), 0644)
+	assert.Equal(t, nil, err)
+
+	ini := New()
+	ini.SetDialect(WindowsDialect)
+	err = ini.ParseFile(filename)
+	assert.Equal(t, nil, err)
+	v, _ := ini.SectionGet("boot", "shell")
+	assert.Equal(t, v, "explorer.exe")
+	v, _ = ini.SectionGet("boot", "#x")
+	assert.Equal(t, v, "1")
+
+	err = os.WriteFile(filename, []byte("[Boot]\r\nShell=cmd.exe\r\n"), 0644)
+	assert.Equal(t, nil, err)
+	err = ini.Reload()
+	assert.Equal(t, nil, err)
+	v, _ = ini.SectionGet("boot", "shell")
+	assert.Equal(t, v, "cmd.exe")
+	assert.Equal(t, ini.Clone().Dialect().LineSeparator, "\r\n")
+}
+
+func TestLegacyDialect(t *testing.T) {
+	ini := New()
+	d := ini.Dialect()
+	assert.Equal(t, d.LineSeparator, DefaultLineSeparator)
+	assert.Equal(t, d.KeyValueSeparators, []string{DefaultKeyValueSeparator})
+	assert.Equal(t, len(d.CommentPrefixes), 0)
+	assert.Equal(t, d.SectionStart, "")
+
+	// Without a dialect '#' is always a comment, and ';' only if comments
+	// are skipped
+	err := ini.Parse([]byte("#c||a:1"), "||", ":")
+	assert.Equal(t, nil, err)
+	err = ini.Parse([]byte(";c||a:1"), "||", ":")
+	assert.NotEqual(t, nil, err)
+	ini.SetSkipCommits(true)
+	ini.SetParseSection(true)
+	ini.SetTrimQuotes(true)
+	err = ini.Parse([]byte("#a\nb=\"1'"), "\n", "=")
+	assert.Equal(t, nil, err)
+	v, _ := ini.Get("b")
+	assert.Equal(t, v, "\"1'") // not a matching pair
+
+	d = ini.Dialect()
+	assert.Equal(t, d.CommentPrefixes, []string{";", "#"})
+	assert.Equal(t, d.SectionStart+d.SectionEnd, "[]")
+	assert.Equal(t, d.Quotes, `"'`)
+
+	ini.SetDialect(DefaultDialect)
+	ini.SetSkipCommits(false)
+	ini.SetParseSection(false)
+	ini.SetTrimQuotes(false)
+	d = ini.Dialect()
+	assert.Equal(t, len(d.CommentPrefixes), 0)
+	assert.Equal(t, d.SectionStart, "")
+	assert.Equal(t, d.Quotes, "")
+}
@@ -0,0 +1,161 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"bytes"
+	"sort"
+)
+
+// DiffKind is the kind of a difference between two INI documents
+type DiffKind int
+
+const (
+	DiffAdded DiffKind = iota
+	DiffRemoved
+	DiffChanged
+)
+
+func (k DiffKind) String() string {
+	switch k {
+	case DiffAdded:
+		return "added"
+	case DiffRemoved:
+		return "removed"
+	case DiffChanged:
+		return "changed"
+	}
+	return "unknown"
+}
+
+// KeyDiff describes a key which differs between two INI documents.
+// Old is empty for an added key and New is empty for a removed key.
+type KeyDiff struct {
+	Kind    DiffKind
+	Section string
+	Key     string
+	Old     string
+	New     string
+}
+
+// SectionDiff describes a section which differs between two INI documents.
+// Keys holds the differences of the keys in that section, sorted by key.
+type SectionDiff struct {
+	Kind    DiffKind
+	Section string
+	Keys    []KeyDiff
+}
+
+// DiffResult is the structural difference between two INI documents,
+// sorted by section name. The default section comes first.
+type DiffResult struct {
+	Sections []SectionDiff
+}
+
+// Diff compares a to b and returns what b adds, removes and changes
+// relative to a. Neither a nor b is changed.
+func Diff(a, b *INI) *DiffResult {
+	names := make(map[string]bool)
+	for section := range a.sections {
+		names[section] = true
+	}
+	for section := range b.sections {
+		names[section] = true
+	}
+
+	d := &DiffResult{}
+	for _, section := range sortedKeys(names) {
+		akv, inA := a.sections[section]
+		bkv, inB := b.sections[section]
+
+		// The default section always exists implicitly
+		sd := SectionDiff{Section: section, Kind: DiffChanged}
+		switch {
+		case section == DefaultSection:
+		case !inA:
+			sd.Kind = DiffAdded
+		case !inB:
+			sd.Kind = DiffRemoved
+		}
+
+		keys := make(map[string]bool)
+		for key := range akv {
+			keys[key] = true
+		}
+		for key := range bkv {
+			keys[key] = true
+		}
+		for _, key := range sortedKeys(keys) {
+			old, inA := akv[key]
+			value, inB := bkv[key]
+			switch {
+			case !inA:
+				sd.Keys = append(sd.Keys, KeyDiff{DiffAdded, section, key, "", value})
+			case !inB:
+				sd.Keys = append(sd.Keys, KeyDiff{DiffRemoved, section, key, old, ""})
+			case old != value:
+				sd.Keys = append(sd.Keys, KeyDiff{DiffChanged, section, key, old, value})
+			}
+		}
+
+		if sd.Kind != DiffChanged || len(sd.Keys) > 0 {
+			d.Sections = append(d.Sections, sd)
+		}
+	}
+	return d
+}
+
+// Equal reports whether there is no difference at all
+func (d *DiffResult) Equal() bool {
+	return len(d.Sections) == 0
+}
+
+// Keys returns the differences of all the keys
+func (d *DiffResult) Keys() []KeyDiff {
+	var keys []KeyDiff
+	for _, sd := range d.Sections {
+		keys = append(keys, sd.Keys...)
+	}
+	return keys
+}
+
+// String renders the difference in a format similar to a unified diff:
+// removed lines have a leading '-', added lines a leading '+', and
+// the header of a section which exists on both sides a leading ' '.
+func (d *DiffResult) String() string {
+	var buf bytes.Buffer
+	for _, sd := range d.Sections {
+		if sd.Section != DefaultSection {
+			switch sd.Kind {
+			case DiffAdded:
+				buf.WriteByte('+')
+			case DiffRemoved:
+				buf.WriteByte('-')
+			default:
+				buf.WriteByte(' ')
+			}
+			buf.WriteString("[" + sd.Section + "]\n")
+		}
+		for _, kd := range sd.Keys {
+			if kd.Kind != DiffAdded {
+				buf.WriteString("-" + kd.Key + DefaultKeyValueSeparator + kd.Old + "\n")
+			}
+			if kd.Kind != DiffRemoved {
+				buf.WriteString("+" + kd.Key + DefaultKeyValueSeparator + kd.New + "\n")
+			}
+		}
+	}
+	return buf.String()
+}
+
+// sortedKeys returns the keys of m in increasing order
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
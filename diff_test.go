@@ -0,0 +1,66 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestDiff(t *testing.T) {
+	a := New()
+	err := a.ParseFile(filepath.Join(getTestDataDir(t), "common.ini"))
+	assert.Equal(t, nil, err)
+
+	b, err := LoadInheritedINI(filepath.Join(getTestDataDir(t), "project.ini"))
+	assert.Equal(t, nil, err)
+
+	d := Diff(a, a)
+	assert.Equal(t, d.Equal(), true)
+	assert.Equal(t, d.String(), "")
+
+	d = Diff(a, b)
+	assert.Equal(t, d.Equal(), false)
+	assert.Equal(t, len(d.Sections), 2)
+	assert.Equal(t, d.Keys(), []KeyDiff{
+		{DiffChanged, "", "combo", "common", "test"},
+		{DiffChanged, "", "debug", "0", "1"},
+		{DiffAdded, "", "inherited_from", "", "common.ini"},
+		{DiffAdded, "", "local", "", "0"},
+		{DiffAdded, "", "mid", "", "c4ca4238a0b923820dcc509a6f75849b"},
+		{DiffChanged, "", "product", "common", "test"},
+		{DiffChanged, "sss", "a", "aval", "aaval"},
+		{DiffAdded, "sss", "c", "", "ccval"},
+	})
+	assert.Equal(t, d.String(), "-combo=common\n+combo=test\n"+
+		"-debug=0\n+debug=1\n"+
+		"+inherited_from=common.ini\n"+
+		"+local=0\n+mid=c4ca4238a0b923820dcc509a6f75849b\n"+
+		"-product=common\n+product=test\n"+
+		" [sss]\n-a=aval\n+a=aaval\n+c=ccval\n")
+}
+
+func TestDiffSections(t *testing.T) {
+	a := New()
+	a.SectionSet("old", "k", "v")
+	b := New()
+	err := b.Parse([]byte("[new]\nk=v\n"), "\n", "=")
+	assert.NotEqual(t, nil, err) // parseSection is off
+	b = New()
+	b.SetParseSection(true)
+	err = b.Parse([]byte("[new]\nk=v\n"), "\n", "=")
+	assert.Equal(t, nil, err)
+
+	d := Diff(a, b)
+	assert.Equal(t, len(d.Sections), 2)
+	assert.Equal(t, d.Sections[0].Kind, DiffAdded)
+	assert.Equal(t, d.Sections[0].Section, "new")
+	assert.Equal(t, d.Sections[1].Kind, DiffRemoved)
+	assert.Equal(t, d.Sections[1].Section, "old")
+	assert.Equal(t, d.String(), "+[new]\n+k=v\n-[old]\n-k=v\n")
+	assert.Equal(t, DiffRemoved.String(), "removed")
+}
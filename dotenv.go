@@ -0,0 +1,110 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"bytes"
+	"os"
+	"strings"
+)
+
+// trimExport removes the shell keyword "export" before the key
+func trimExport(key []byte) []byte {
+	const export = "export"
+	if len(key) > len(export) && bytes.HasPrefix(key, []byte(export)) && isSpace(key[len(export)]) {
+		return bytes.TrimLeft(key[len(export):], " \t")
+	}
+	return key
+}
+
+// define records the value of the key parsed, which the next values may
+// refer to. See Dialect.Expand.
+func (p *Parser) define(t token) {
+	switch t.typ {
+	case SectionEvent:
+		clear(p.vars)
+	case KeyValueEvent:
+		if p.vars == nil {
+			p.vars = make(map[string]string)
+		}
+		p.vars[string(t.name)] = string(t.value)
+	}
+}
+
+// expand replaces the references to the variables in the value v, which
+// may be quoted. The values of the variables are escaped in a double
+// quoted value, so that unquoting it restores them. A single quoted value
+// is returned unchanged.
+func (p *Parser) expand(v []byte) []byte {
+	if bytes.IndexByte(v, '$') < 0 {
+		return v
+	}
+	quoted := false
+	if quotedLen(v, p.d.Quotes) == len(v) {
+		if v[0] != '"' {
+			return v
+		}
+		quoted = true
+	}
+
+	out := make([]byte, 0, len(v))
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		switch {
+		case c == '\\' && i+1 < len(v):
+			// The other escape sequences are kept for unquote
+			if v[i+1] != '$' {
+				out = append(out, c)
+			}
+			out = append(out, v[i+1])
+			i++
+		case c == '$':
+			name, n := varName(v[i+1:])
+			if n == 0 {
+				out = append(out, c)
+				continue
+			}
+			value := p.lookup(name)
+			if quoted {
+				value = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+			}
+			out = append(out, value...)
+			i += n
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// varName returns the name of the variable referred to at the start of v
+// as {NAME} or NAME, and the length of the reference, which is 0 if there
+// is none
+func varName(v []byte) (string, int) {
+	if len(v) > 0 && v[0] == '{' {
+		end := bytes.IndexByte(v, '}')
+		if end <= 1 {
+			return "", 0
+		}
+		return string(v[1:end]), end + 1
+	}
+	n := 0
+	for ; n < len(v); n++ {
+		c := v[n]
+		if !(c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || n > 0 && '0' <= c && c <= '9') {
+			break
+		}
+	}
+	return string(v[:n]), n
+}
+
+// lookup returns the value of the variable name, defined before in the
+// same section or in the environment
+func (p *Parser) lookup(name string) string {
+	if value, ok := p.vars[name]; ok {
+		return value
+	}
+	return os.Getenv(name)
+}
@@ -0,0 +1,105 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+const dotenvTestData = `# comment
+export DB_HOST=localhost
+DB_PORT=5432 # the port
+DB_URL="postgres://${DB_HOST}:$DB_PORT/db"
+LITERAL='${DB_HOST}\n'
+MULTI="line1\nline2"
+ESCAPED="cost \$5 \"quoted\""
+PLAIN=a\$b$
+HOME_DIR=${GOINI_TEST_HOME}
+MISSING=${GOINI_TEST_MISSING}x
+HASH=a#b
+SPACED = ' x '
+QUOTE=a"b\c
+QUOTED="${QUOTE}"
+exported=1
+`
+
+func TestParseDotenv(t *testing.T) {
+	t.Setenv("GOINI_TEST_HOME", "/home/me")
+	t.Setenv("DB_PORT", "1") // The keys defined before win
+	ini := parseTestINI(t, dotenvTestData, withDialect(DotenvDialect))
+	tests := []struct {
+		key, value string
+	}{
+		{"DB_HOST", "localhost"},
+		{"DB_PORT", "5432"},
+		{"DB_URL", "postgres://localhost:5432/db"},
+		{"LITERAL", `${DB_HOST}\n`},
+		{"MULTI", "line1\nline2"},
+		{"ESCAPED", `cost $5 "quoted"`},
+		{"PLAIN", "a$b$"},
+		{"HOME_DIR", "/home/me"},
+		{"MISSING", "x"},
+		{"HASH", "a#b"},
+		{"SPACED", " x "},
+		{"QUOTE", `a"b\c`},
+		{"QUOTED", `a"b\c`},
+		{"exported", "1"},
+	}
+	for _, test := range tests {
+		v, ok := ini.Get(test.key)
+		assert.Equal(t, v, test.value, test.key)
+		assert.Equal(t, ok, true, test.key)
+	}
+	assert.Equal(t, len(ini.GetAll()[DefaultSection]), len(tests))
+	assert.Equal(t, ini.InlineComment("", "DB_PORT"), "# the port")
+}
+
+func TestDotenvParserEvents(t *testing.T) {
+	d := DotenvDialect
+	d.SectionStart, d.SectionEnd = "[", "]"
+	p := NewParser(strings.NewReader("A=1\nB=$A\n[s]\nC=${A}\nA=2\nD=$A\n"), d)
+	var values []string
+	for {
+		e, err := p.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.Equal(t, nil, err)
+		if e.Type == KeyValueEvent {
+			values = append(values, e.Key+"="+e.Value)
+		}
+	}
+	// The references are resolved in the same section
+	assert.Equal(t, values, []string{"A=1", "B=1", "C=", "A=2", "D=2"})
+}
+
+func TestWriteDotenv(t *testing.T) {
+	ini := New()
+	ini.SetDialect(DotenvDialect)
+	ini.Set("PRICE", "$5")
+	ini.Set("MSG", "hello # world")
+	ini.Set("NL", "a\nb")
+	ini.Set("PLAIN", "v")
+	out := writeString(t, ini)
+	assert.Equal(t, out, `PRICE="\$5"
+MSG="hello # world"
+NL="a\nb"
+PLAIN=v
+`)
+
+	c := parseTestINI(t, out, withDialect(DotenvDialect))
+	assert.Equal(t, c.Equal(ini), true)
+}
+
+func TestTrimExport(t *testing.T) {
+	assert.Equal(t, string(trimExport([]byte("export  A"))), "A")
+	assert.Equal(t, string(trimExport([]byte("export\tA"))), "A")
+	assert.Equal(t, string(trimExport([]byte("exported"))), "exported")
+	assert.Equal(t, string(trimExport([]byte("export"))), "export")
+}
@@ -0,0 +1,92 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SetCaseInsensitive makes the names of the sections and/or the keys case
+// insensitive for lookups, modifications, merges and inheritance, while
+// Write keeps the spelling the names were first stored with. It should be
+// called before any data is stored. The internal maps returned by GetAll
+// and GetKvmap are keyed by the folded names.
+func (ini *INI) SetCaseInsensitive(sections, keys bool) {
+	ini.foldSections = sections
+	ini.foldKeys = keys
+	ini.cache = nil
+	if ini.dialect != nil {
+		ini.dialect.CaseInsensitiveSections = sections
+		ini.dialect.CaseInsensitiveKeys = keys
+	}
+}
+
+// foldCase returns the Unicode case folding of s which is used to compare
+// names case insensitively
+func foldCase(s string) string {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c >= utf8.RuneSelf || ('A' <= c && c <= 'Z') {
+			return strings.Map(func(r rune) rune {
+				return unicode.ToLower(unicode.ToUpper(r))
+			}, s)
+		}
+	}
+	return s
+}
+
+// sectionName returns the name under which section is stored
+func (ini *INI) sectionName(section string) string {
+	return storedSection(section, ini.foldSections, ini.subsections)
+}
+
+// keyName returns the name under which key is stored
+func (ini *INI) keyName(key string) string {
+	if ini.foldKeys {
+		return foldCase(key)
+	}
+	return key
+}
+
+// spellSection returns the spelling of the stored section name
+func (ini *INI) spellSection(section string) string {
+	if s, ok := ini.sectionSpelling[section]; ok {
+		return s
+	}
+	return section
+}
+
+// spellKey returns the spelling of the stored key name of section
+func (ini *INI) spellKey(section, key string) string {
+	if s, ok := ini.keySpelling[lineKey{section, key}]; ok {
+		return s
+	}
+	return key
+}
+
+// recordSectionSpelling records the spelling of a new section
+func (ini *INI) recordSectionSpelling(section, spelling string) {
+	if section == spelling {
+		delete(ini.sectionSpelling, section)
+		return
+	}
+	if ini.sectionSpelling == nil {
+		ini.sectionSpelling = make(map[string]string)
+	}
+	ini.sectionSpelling[section] = spelling
+}
+
+// recordKeySpelling records the spelling of a new key of section
+func (ini *INI) recordKeySpelling(section, key, spelling string) {
+	if key == spelling {
+		delete(ini.keySpelling, lineKey{section, key})
+		return
+	}
+	if ini.keySpelling == nil {
+		ini.keySpelling = make(map[lineKey]string)
+	}
+	ini.keySpelling[lineKey{section, key}] = spelling
+}
@@ -0,0 +1,152 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+const foldTestData = "Name=goini\n[Server]\nHost=localhost\nPort=8080\n"
+
+func caseInsensitive(sections, keys bool) func(ini *INI) {
+	return func(ini *INI) {
+		ini.SetParseSection(true)
+		ini.SetCaseInsensitive(sections, keys)
+	}
+}
+
+func TestCaseInsensitiveGet(t *testing.T) {
+	ini := parseTestINI(t, foldTestData, caseInsensitive(true, true))
+
+	v, ok := ini.Get("NAME")
+	assert.Equal(t, v, "goini")
+	assert.Equal(t, ok, true)
+	v, ok = ini.SectionGet("server", "host")
+	assert.Equal(t, v, "localhost")
+	assert.Equal(t, ok, true)
+	port, ok := ini.SectionGetInt("SERVER", "PORT")
+	assert.Equal(t, port, 8080)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, ini.HasSection("sErVeR"), true)
+	assert.Equal(t, ini.HasKey("server", "port"), true)
+	assert.Equal(t, ini.SectionLine("server"), 2)
+	assert.Equal(t, ini.Line("server", "port"), 4)
+}
+
+func TestCaseInsensitiveSectionsOnly(t *testing.T) {
+	ini := parseTestINI(t, foldTestData, caseInsensitive(true, false))
+
+	_, ok := ini.SectionGet("server", "Host")
+	assert.Equal(t, ok, true)
+	_, ok = ini.SectionGet("server", "host")
+	assert.Equal(t, ok, false)
+}
+
+func TestCaseSensitiveByDefault(t *testing.T) {
+	ini := parseTestINI(t, foldTestData, caseInsensitive(false, false))
+
+	_, ok := ini.SectionGet("server", "Host")
+	assert.Equal(t, ok, false)
+	_, ok = ini.SectionGet("Server", "Host")
+	assert.Equal(t, ok, true)
+}
+
+func TestCaseInsensitiveWriteKeepsSpelling(t *testing.T) {
+	ini := parseTestINI(t, foldTestData, caseInsensitive(true, true))
+
+	ini.SectionSet("SERVER", "PORT", "9090")
+	ini.SectionSet("server", "Timeout", "3s")
+	ini.SectionSet("Client", "Retry", "2")
+	ini.Delete("server", "HOST")
+	assert.Equal(t, writeString(t, ini),
+		"Name=goini\n[Server]\nPort=9090\nTimeout=3s\n[Client]\nRetry=2\n")
+
+	err := ini.RenameSection("client", "Backend")
+	assert.Equal(t, nil, err)
+	err = ini.RenameKey("backend", "retry", "Retries")
+	assert.Equal(t, nil, err)
+	ini.DeleteSection("SERVER")
+	assert.Equal(t, writeString(t, ini), "Name=goini\n[Backend]\nRetries=2\n")
+}
+
+func TestCaseInsensitiveUnicode(t *testing.T) {
+	ini := New()
+	ini.SetCaseInsensitive(true, true)
+
+	// KELVIN SIGN folds to k, and the final sigma to sigma
+	ini.SectionSet("Straße", "K", "1")
+	ini.SectionSet("ΟΔΟΣ", "σ", "2")
+	v, ok := ini.SectionGet("STRAßE", "k")
+	assert.Equal(t, v, "1")
+	assert.Equal(t, ok, true)
+	v, ok = ini.SectionGet("οδος", "ς")
+	assert.Equal(t, v, "2")
+	assert.Equal(t, ok, true)
+}
+
+func TestCaseInsensitiveMerge(t *testing.T) {
+	ini := parseTestINI(t, foldTestData, caseInsensitive(true, true))
+	from := New()
+	from.SectionSet("SERVER", "HOST", "example.com")
+	from.SectionSet("SERVER", "Timeout", "3s")
+
+	ini.Merge(from, false)
+	v, _ := ini.SectionGet("server", "host")
+	assert.Equal(t, v, "localhost")
+	ini.Merge(from, true)
+	v, _ = ini.SectionGet("server", "host")
+	assert.Equal(t, v, "example.com")
+	assert.Equal(t, writeString(t, ini),
+		"Name=goini\n[Server]\nHost=example.com\nPort=8080\nTimeout=3s\n")
+}
+
+func TestCaseInsensitiveInheritance(t *testing.T) {
+	dir := t.TempDir()
+	common := filepath.Join(dir, "common.ini")
+	app := filepath.Join(dir, "app.ini")
+	err := os.WriteFile(common, []byte("[Server]\nHost=localhost\nPort=80\n"), 0644)
+	assert.Equal(t, nil, err)
+	err = os.WriteFile(app, []byte("inherited_from=common.ini\n[SERVER]\nPORT=8080\n"), 0644)
+	assert.Equal(t, nil, err)
+
+	ini, err := LoadInheritedINIDialect(app, GitConfigDialect)
+	assert.Equal(t, nil, err)
+	v, _ := ini.SectionGet("server", "port")
+	assert.Equal(t, v, "8080")
+	v, _ = ini.SectionGet("server", "host")
+	assert.Equal(t, v, "localhost")
+	kv, _ := ini.GetKvmap("Server")
+	assert.Equal(t, len(kv), 2)
+
+	// Case sensitive inheritance keeps both spellings
+	ini, err = LoadInheritedINI(app)
+	assert.Equal(t, nil, err)
+	v, _ = ini.SectionGet("Server", "Port")
+	assert.Equal(t, v, "80")
+	v, _ = ini.SectionGet("SERVER", "PORT")
+	assert.Equal(t, v, "8080")
+}
+
+func TestCaseInsensitiveAliasAndSubscribe(t *testing.T) {
+	ini := parseTestINI(t, foldTestData, caseInsensitive(true, true))
+	ini.SetLogger(nil)
+	ini.AddAlias("SERVER", "HOST", "server", "address")
+
+	v, ok := ini.SectionGet("Server", "Address")
+	assert.Equal(t, v, "localhost")
+	assert.Equal(t, ok, true)
+
+	var got []string
+	cancel := ini.Subscribe("SERVER", "port", func(old, new string, deleted bool) {
+		got = append(got, old, new)
+	})
+	defer cancel()
+	ini.SectionSet("server", "PORT", "9090")
+	assert.Equal(t, got, []string{"8080", "9090"})
+}
@@ -0,0 +1,326 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Frozen is an immutable snapshot of an INI, returned by Freeze.
+// The key/value pairs are stored in one flat array sorted by section and
+// key, indexed by one open addressing hash table of the section/key pairs,
+// and the typed values are parsed in advance. So a lookup hashes the names
+// once and needs no parsing. It is safe for concurrent use.
+type Frozen struct {
+	sections     []frozenSection // Sorted by name
+	entries      []frozenEntry   // Sorted by section and key
+	table        []frozenSlot    // The hash table of the entries
+	foldSections bool
+	foldKeys     bool
+	subsections  bool
+}
+
+// frozenSlot is a slot of the hash table. The index is 1-based, so that
+// 0 is an empty slot.
+type frozenSlot struct {
+	hash  uint32
+	index uint32
+}
+
+type frozenSection struct {
+	name       string
+	start, end int // The range of the entries of the section
+	line       int
+	aliasOnly  bool // Whether the section only holds keys resolved through the aliases
+}
+
+type frozenEntry struct {
+	key     string
+	value   string
+	i       int
+	f       float64
+	line    int
+	section uint32 // The index of the section
+	flags   uint8
+}
+
+// The flags of a frozenEntry
+const (
+	frozenBool  = 1 << iota // The value is a boolean
+	frozenTrue              // The value is true
+	frozenAlias             // The key is only resolved through the aliases
+)
+
+// frozenHash returns the FNV-1a hash of the section/key pair
+func frozenHash(section, key string) uint32 {
+	const prime = 16777619
+	h := uint32(2166136261)
+	for i := 0; i < len(section); i++ {
+		h = (h ^ uint32(section[i])) * prime
+	}
+	h = (h ^ 0xff) * prime // 0xff is never part of valid UTF-8
+	for i := 0; i < len(key); i++ {
+		h = (h ^ uint32(key[i])) * prime
+	}
+	return h
+}
+
+// Freeze returns an immutable snapshot of this INI, which is not affected by
+// later changes. It has the same getters as INI. The deprecated keys are
+// resolved as INI does when Freeze is called, but no warning is logged.
+func (ini *INI) Freeze() *Frozen {
+	f := &Frozen{foldSections: ini.foldSections, foldKeys: ini.foldKeys, subsections: ini.subsections}
+
+	// The aliases add the keys which are not set under the other name
+	extra := make(map[string][]string)
+	for _, alias := range ini.aliases.list {
+		old, new := ini.aliasKeys(alias)
+		for _, k := range []lineKey{old, new} {
+			if _, ok := ini.sections[k.section][k.key]; !ok {
+				if _, _, ok := ini.lookupAlias(k.section, k.key); ok {
+					extra[k.section] = append(extra[k.section], k.key)
+				}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(ini.sections)+len(extra))
+	for section := range ini.sections {
+		names = append(names, section)
+	}
+	for section := range extra {
+		if _, ok := ini.sections[section]; !ok {
+			names = append(names, section)
+		}
+	}
+	sort.Strings(names)
+
+	for _, section := range names {
+		kv := ini.sections[section]
+		keys := make([]string, 0, len(kv)+len(extra[section]))
+		for key := range kv {
+			keys = append(keys, key)
+		}
+		for _, key := range extra[section] {
+			if _, ok := kv[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		keys = uniqueSorted(keys)
+
+		start := len(f.entries)
+		for _, key := range keys {
+			value, ok := kv[key]
+			if !ok {
+				value, _, _ = ini.lookupAlias(section, key)
+			}
+			e := newFrozenEntry(key, ini.ownedValue(value), ini.keyLines[lineKey{section, key}])
+			e.section = uint32(len(f.sections))
+			if !ok {
+				e.flags |= frozenAlias
+			}
+			f.entries = append(f.entries, e)
+		}
+		f.sections = append(f.sections, frozenSection{
+			name:      section,
+			start:     start,
+			end:       len(f.entries),
+			line:      ini.sectionLines[section],
+			aliasOnly: kv == nil,
+		})
+	}
+	f.buildTable()
+	return f
+}
+
+// buildTable builds the hash table of the entries, which is at most half
+// full
+func (f *Frozen) buildTable() {
+	size := 1
+	for size < 2*len(f.entries) {
+		size <<= 1
+	}
+	f.table = make([]frozenSlot, size)
+	mask := uint32(size - 1)
+	for _, s := range f.sections {
+		for i := s.start; i < s.end; i++ {
+			h := frozenHash(s.name, f.entries[i].key)
+			j := h & mask
+			for f.table[j].index != 0 {
+				j = (j + 1) & mask
+			}
+			f.table[j] = frozenSlot{h, uint32(i + 1)}
+		}
+	}
+}
+
+func newFrozenEntry(key, value string, line int) frozenEntry {
+	// A value which is not a number is 0, as INI.SectionGetInt returns
+	e := frozenEntry{key: key, value: value, line: line}
+	if i, err := strconv.Atoi(value); err == nil {
+		e.i = i
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		e.f = f
+	}
+	if b, ok := parseBool(value); ok {
+		e.flags |= frozenBool
+		if b {
+			e.flags |= frozenTrue
+		}
+	}
+	return e
+}
+
+// uniqueSorted removes the duplicates from the sorted names
+func uniqueSorted(names []string) []string {
+	if len(names) < 2 {
+		return names
+	}
+	n := 1
+	for _, name := range names[1:] {
+		if name != names[n-1] {
+			names[n] = name
+			n++
+		}
+	}
+	return names[:n]
+}
+
+// section returns the section named name, or nil if it does not exist
+func (f *Frozen) section(name string) *frozenSection {
+	name = storedSection(name, f.foldSections, f.subsections)
+	lo, hi := 0, len(f.sections)
+	for lo < hi {
+		m := int(uint(lo+hi) >> 1)
+		if f.sections[m].name < name {
+			lo = m + 1
+		} else {
+			hi = m
+		}
+	}
+	if lo < len(f.sections) && f.sections[lo].name == name {
+		return &f.sections[lo]
+	}
+	return nil
+}
+
+// entry returns the entry of key in section, or nil if it does not exist
+func (f *Frozen) entry(section, key string) *frozenEntry {
+	section = storedSection(section, f.foldSections, f.subsections)
+	if f.foldKeys {
+		key = foldCase(key)
+	}
+
+	h := frozenHash(section, key)
+	mask := uint32(len(f.table) - 1)
+	for j := h & mask; f.table[j].index != 0; j = (j + 1) & mask {
+		if f.table[j].hash != h {
+			continue
+		}
+		e := &f.entries[f.table[j].index-1]
+		if e.key == key && f.sections[e.section].name == section {
+			return e
+		}
+	}
+	return nil
+}
+
+// Get looks up a value for a key in the default section. See INI.Get.
+func (f *Frozen) Get(key string) (string, bool) {
+	return f.SectionGet(DefaultSection, key)
+}
+
+// GetInt gets value as int. See INI.GetInt.
+func (f *Frozen) GetInt(key string) (int, bool) {
+	return f.SectionGetInt(DefaultSection, key)
+}
+
+// GetFloat gets value as float64. See INI.GetFloat.
+func (f *Frozen) GetFloat(key string) (float64, bool) {
+	return f.SectionGetFloat(DefaultSection, key)
+}
+
+// GetBool gets value as bool. See INI.GetBool.
+func (f *Frozen) GetBool(key string) (bool, bool) {
+	return f.SectionGetBool(DefaultSection, key)
+}
+
+// SectionGet looks up a value for a key in a section. See INI.SectionGet.
+func (f *Frozen) SectionGet(section, key string) (string, bool) {
+	if e := f.entry(section, key); e != nil {
+		return e.value, true
+	}
+	return "", false
+}
+
+// SectionGetInt gets value as int. See INI.SectionGetInt.
+func (f *Frozen) SectionGetInt(section, key string) (int, bool) {
+	if e := f.entry(section, key); e != nil {
+		return e.i, true
+	}
+	return 0, false
+}
+
+// SectionGetFloat gets value as float64. See INI.SectionGetFloat.
+func (f *Frozen) SectionGetFloat(section, key string) (float64, bool) {
+	if e := f.entry(section, key); e != nil {
+		return e.f, true
+	}
+	return 0.0, false
+}
+
+// SectionGetBool gets value as bool. See INI.SectionGetBool.
+func (f *Frozen) SectionGetBool(section, key string) (bool, bool) {
+	if e := f.entry(section, key); e != nil && e.flags&frozenBool != 0 {
+		return e.flags&frozenTrue != 0, true
+	}
+	return false, false
+}
+
+// HasSection reports whether the section exists
+func (f *Frozen) HasSection(section string) bool {
+	s := f.section(section)
+	return s != nil && !s.aliasOnly
+}
+
+// HasKey reports whether the key exists in section
+func (f *Frozen) HasKey(section, key string) bool {
+	return f.entry(section, key) != nil
+}
+
+// GetKvmap returns a copy of all the keys under section. See INI.GetKvmap.
+func (f *Frozen) GetKvmap(section string) (Kvmap, bool) {
+	s := f.section(section)
+	if s == nil || s.aliasOnly {
+		return nil, false
+	}
+	kv := make(Kvmap, s.end-s.start)
+	for _, e := range f.entries[s.start:s.end] {
+		if e.flags&frozenAlias == 0 {
+			kv[e.key] = e.value
+		}
+	}
+	return kv, true
+}
+
+// Line returns the line number of the key in section. See INI.Line.
+func (f *Frozen) Line(section, key string) int {
+	if e := f.entry(section, key); e != nil {
+		return e.line
+	}
+	return 0
+}
+
+// SectionLine returns the line number of the header of section.
+// See INI.SectionLine.
+func (f *Frozen) SectionLine(section string) int {
+	if s := f.section(section); s != nil {
+		return s.line
+	}
+	return 0
+}
@@ -0,0 +1,204 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestFreezeSameAsINI(t *testing.T) {
+	filename := filepath.Join(getTestDataDir(t), "ini_parser_testfile.ini")
+	ini := New()
+	err := ini.ParseFile(filename)
+	assert.Equal(t, nil, err)
+	f := ini.Freeze()
+
+	sections := []string{"", "sss", "ddd", "none"}
+	keys := []string{"mid", "product", "combo", "version", "appext", "aa", "debug", "age", "height", "none"}
+	for _, section := range sections {
+		assert.Equal(t, f.HasSection(section), ini.HasSection(section))
+		assert.Equal(t, f.SectionLine(section), ini.SectionLine(section))
+		kv1, ok1 := f.GetKvmap(section)
+		kv2, ok2 := ini.GetKvmap(section)
+		assert.Equal(t, ok1, ok2)
+		assert.Equal(t, len(kv1), len(kv2))
+		for k, v := range kv2 {
+			assert.Equal(t, kv1[k], v)
+		}
+
+		for _, key := range keys {
+			v1, ok1 := f.SectionGet(section, key)
+			v2, ok2 := ini.SectionGet(section, key)
+			assert.Equal(t, v1, v2)
+			assert.Equal(t, ok1, ok2)
+			i1, ok1 := f.SectionGetInt(section, key)
+			i2, ok2 := ini.SectionGetInt(section, key)
+			assert.Equal(t, i1, i2)
+			assert.Equal(t, ok1, ok2)
+			f1, ok1 := f.SectionGetFloat(section, key)
+			f2, ok2 := ini.SectionGetFloat(section, key)
+			assert.Equal(t, f1, f2)
+			assert.Equal(t, ok1, ok2)
+			b1, ok1 := f.SectionGetBool(section, key)
+			b2, ok2 := ini.SectionGetBool(section, key)
+			assert.Equal(t, b1, b2)
+			assert.Equal(t, ok1, ok2)
+			assert.Equal(t, f.HasKey(section, key), ini.HasKey(section, key))
+			assert.Equal(t, f.Line(section, key), ini.Line(section, key))
+		}
+	}
+
+	v, _ := f.Get("version")
+	assert.Equal(t, v, "4.4")
+	fv, _ := f.GetFloat("version")
+	assert.Equal(t, fv, 4.4)
+	b, ok := f.GetBool("debug")
+	assert.Equal(t, b, false)
+	assert.Equal(t, ok, true)
+	i, ok := f.GetInt("product")
+	assert.Equal(t, i, 0)
+	assert.Equal(t, ok, true)
+}
+
+func TestFreezeIsASnapshot(t *testing.T) {
+	ini := New()
+	ini.SectionSet("s", "a", "1")
+	f := ini.Freeze()
+
+	ini.SectionSet("s", "a", "2")
+	ini.SectionSet("s", "b", "3")
+	v, _ := f.SectionGet("s", "a")
+	assert.Equal(t, v, "1")
+	assert.Equal(t, f.HasKey("s", "b"), false)
+
+	kv, _ := f.GetKvmap("s")
+	kv["a"] = "x"
+	v, _ = f.SectionGet("s", "a")
+	assert.Equal(t, v, "1")
+}
+
+func TestFreezeEmpty(t *testing.T) {
+	f := New().Freeze()
+	_, ok := f.Get("a")
+	assert.Equal(t, ok, false)
+	assert.Equal(t, f.HasSection(""), false)
+}
+
+func TestFreezeCaseInsensitive(t *testing.T) {
+	ini := parseTestINI(t, foldTestData, caseInsensitive(true, true))
+	f := ini.Freeze()
+	port, ok := f.SectionGetInt("SERVER", "port")
+	assert.Equal(t, port, 8080)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, f.HasSection("server"), true)
+}
+
+func TestFreezeAliases(t *testing.T) {
+	ini := New()
+	ini.SetLogger(nil)
+	ini.SectionSet("old", "host", "localhost")
+	ini.SectionSet("new", "port", "80")
+	ini.AddAlias("old", "host", "server", "address")
+	ini.AddAlias("old", "port", "new", "port")
+	f := ini.Freeze()
+
+	v, ok := f.SectionGet("server", "address")
+	assert.Equal(t, v, "localhost")
+	assert.Equal(t, ok, true)
+	v, ok = f.SectionGet("old", "port")
+	assert.Equal(t, v, "80")
+	assert.Equal(t, ok, true)
+
+	// The aliases do not create sections nor keys
+	assert.Equal(t, f.HasSection("server"), ini.HasSection("server"))
+	kv, _ := f.GetKvmap("old")
+	assert.Equal(t, kv, Kvmap{"host": "localhost"})
+}
+
+func TestFrozenConcurrentReads(t *testing.T) {
+	f := newBenchmarkINI().Freeze()
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				v, ok := f.SectionGetInt("section"+strconv.Itoa(i%10), "key"+strconv.Itoa(i%20))
+				if !ok || v != i%20 {
+					t.Errorf("unexpected value %d", v)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// newBenchmarkINI returns an INI with 10 sections of 20 keys
+func newBenchmarkINI() *INI {
+	ini := New()
+	for s := 0; s < 10; s++ {
+		for k := 0; k < 20; k++ {
+			ini.SectionSet("section"+strconv.Itoa(s), "key"+strconv.Itoa(k), strconv.Itoa(k))
+		}
+	}
+	return ini
+}
+
+// run this by command : go test -test.bench="SectionGet"
+func BenchmarkINISectionGet(b *testing.B) {
+	ini := newBenchmarkINI()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ini.SectionGet("section5", "key13")
+	}
+}
+
+func BenchmarkFrozenSectionGet(b *testing.B) {
+	f := newBenchmarkINI().Freeze()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		f.SectionGet("section5", "key13")
+	}
+}
+
+func BenchmarkINISectionGetInt(b *testing.B) {
+	ini := newBenchmarkINI()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ini.SectionGetInt("section5", "key13")
+	}
+}
+
+func BenchmarkFrozenSectionGetInt(b *testing.B) {
+	f := newBenchmarkINI().Freeze()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		f.SectionGetInt("section5", "key13")
+	}
+}
+
+func BenchmarkINISectionGetParallel(b *testing.B) {
+	ini := newBenchmarkINI()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			ini.SectionGetInt("section5", "key13")
+		}
+	})
+}
+
+func BenchmarkFrozenSectionGetParallel(b *testing.B) {
+	f := newBenchmarkINI().Freeze()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			f.SectionGetInt("section5", "key13")
+		}
+	})
+}
@@ -0,0 +1,236 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"strings"
+)
+
+// The sections form a hierarchy through their names. Both the dotted
+// names and the git config style names are supported, e.g. :
+//
+//	[server.http]      the subsection http of the section server
+//	[remote "origin"]  the subsection origin of the section remote
+//
+// The git config style names are only understood with SetSubsections or a
+// dialect which has Subsections, e.g. GitConfigDialect. A git config style
+// section is then stored as the dotted name, so it can be looked up by both
+// names, and Write keeps the spelling it was written with. Unlike the dotted
+// names, the name of its subsection is case sensitive and may contain dots.
+// Otherwise it is an ordinary section, e.g. [a "b"] and [a.b] differ.
+
+// SubsectionSeparator separates the names of a section and its subsections,
+// e.g. [server.http] is the subsection http of the section server
+const SubsectionSeparator = "."
+
+// SetSubsections sets whether the git config style sections like
+// [remote "origin"] are stored under their dotted names like remote.origin.
+// It should be called before any data is stored. See Dialect.Subsections.
+func (ini *INI) SetSubsections(v bool) {
+	ini.subsections = v
+	ini.cache = nil
+	if ini.dialect != nil {
+		ini.dialect.Subsections = v
+	}
+}
+
+// ChildSections returns the names of the direct subsections of parent in
+// document order, e.g. the children of "server" are "server.http" and
+// "server.tls". A child is listed if only its own subsections exist, and
+// the children of the default section are the top level sections.
+func (ini *INI) ChildSections(parent string) []string {
+	parent = ini.sectionName(parent)
+	var children []string
+	seen := make(map[string]bool)
+	for _, section := range ini.sectionList() {
+		if section == parent || section == DefaultSection {
+			continue
+		}
+		if _, ok := ini.sections[section]; !ok {
+			continue
+		}
+		child, _, ok := ini.splitDescendant(section, parent)
+		if !ok {
+			continue
+		}
+		if name := ini.sectionName(child); !seen[name] {
+			seen[name] = true
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// Subtree returns a new INI holding the keys of the section parent in its
+// default section, and the descendants of parent as the sections named
+// relative to parent, e.g. the subtree "server" of the sections
+// [server.http] and [remote "origin"] is [http], and the subtree "remote"
+// is [origin]. The parser settings, the line numbers and the inline
+// comments are kept.
+func (ini *INI) Subtree(parent string) *INI {
+	parent = ini.sectionName(parent)
+	c := New()
+	ini.copySettings(c)
+	for _, section := range ini.sectionList() {
+		kv, ok := ini.sections[section]
+		if !ok {
+			continue
+		}
+		var spelling string
+		if section != parent {
+			if _, spelling, ok = ini.splitDescendant(section, parent); !ok {
+				continue
+			}
+		}
+
+		name := c.sectionName(spelling)
+		ckv := c.newSection(name)
+		if name != DefaultSection {
+			c.recordSectionSpelling(name, spelling)
+			c.sectionLines[name] = ini.sectionLines[section]
+		}
+		for _, key := range ini.keyList(section) {
+			value, ok := kv[key]
+			if !ok {
+				continue
+			}
+			if _, found := ckv[key]; !found {
+				c.keyNames[name] = append(c.keyNames[name], key)
+				c.recordKeySpelling(name, key, ini.spellKey(section, key))
+			}
+			ckv[key] = value
+			if line, ok := ini.keyLines[lineKey{section, key}]; ok {
+				c.keyLines[lineKey{name, key}] = line
+			}
+			if comment, ok := ini.comments[lineKey{section, key}]; ok {
+				c.setComment(name, key, comment)
+			}
+		}
+	}
+	return c
+}
+
+// splitDescendant splits the spelling of the stored section, which is a
+// descendant of the stored section parent, into the name of the direct
+// child of parent, and the name of the section relative to parent.
+// It returns false if section is not a descendant of parent.
+func (ini *INI) splitDescendant(section, parent string) (child, relative string, ok bool) {
+	spelling := ini.spellSection(section)
+	var name, sub string
+	quoted := false
+	if ini.subsections {
+		name, sub, quoted = splitSubsection(spelling)
+	}
+	if quoted {
+		section = ini.sectionName(name)
+		if section == parent {
+			return spelling, sub, true
+		}
+	} else {
+		name = spelling
+	}
+
+	// The separators of the parent are skipped in the spelling
+	skip := 0
+	if parent != DefaultSection {
+		if !strings.HasPrefix(section, parent+SubsectionSeparator) {
+			return "", "", false
+		}
+		skip = strings.Count(parent, SubsectionSeparator) + 1
+	}
+	start := 0
+	for ; skip > 0; skip-- {
+		i := strings.Index(name[start:], SubsectionSeparator)
+		if i < 0 {
+			return "", "", false
+		}
+		start += i + len(SubsectionSeparator)
+	}
+
+	relative = spelling[start:]
+	if i := strings.Index(name[start:], SubsectionSeparator); i >= 0 {
+		child = name[:start+i]
+	} else if quoted {
+		child = name
+	} else {
+		child = spelling
+	}
+	return child, relative, true
+}
+
+// splitSubsection splits the git config style section name `name "sub"`
+// into the section name and the subsection name, in which \" and \\ are
+// unescaped. It returns false if section is not of this style.
+func splitSubsection(section string) (name, sub string, ok bool) {
+	n := len(section)
+	if n < 2 || section[n-1] != '"' {
+		return "", "", false
+	}
+	i := strings.IndexAny(section, " \t")
+	if i <= 0 {
+		return "", "", false
+	}
+	rest := strings.TrimLeft(section[i:], " \t")
+	if len(rest) < 2 || rest[0] != '"' {
+		return "", "", false
+	}
+	rest = rest[1 : len(rest)-1]
+	if strings.IndexByte(rest, '\\') < 0 {
+		if strings.IndexByte(rest, '"') >= 0 {
+			return "", "", false
+		}
+		return section[:i], rest, true
+	}
+
+	var b strings.Builder
+	for j := 0; j < len(rest); j++ {
+		c := rest[j]
+		if c == '\\' {
+			if j++; j == len(rest) {
+				return "", "", false // The closing quote is escaped
+			}
+			c = rest[j]
+		} else if c == '"' {
+			return "", "", false
+		}
+		b.WriteByte(c)
+	}
+	return section[:i], b.String(), true
+}
+
+// storedSection returns the name under which section is stored. If
+// subsections is true, a git config style name is stored as the dotted
+// name, of which only the section name is folded if fold is true.
+func storedSection(section string, fold, subsections bool) string {
+	if !subsections {
+		if fold {
+			return foldCase(section)
+		}
+		return section
+	}
+	if name, sub, ok := splitSubsection(section); ok {
+		return storedSection(name, fold, false) + SubsectionSeparator + sub
+	}
+	if fold {
+		return foldCase(section)
+	}
+	return section
+}
+
+// Children returns the views of the direct subsections of the section.
+// See INI.ChildSections.
+func (s *Section) Children() []*Section {
+	var children []*Section
+	for _, name := range s.ini.ChildSections(s.name) {
+		children = append(children, s.ini.Section(name))
+	}
+	return children
+}
+
+// Subtree returns the section and its descendants as a new INI.
+// See INI.Subtree.
+func (s *Section) Subtree() *INI {
+	return s.ini.Subtree(s.name)
+}
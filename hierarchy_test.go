@@ -0,0 +1,171 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+const hierarchyTestData = `name=app
+[server]
+port=80
+[server.http]
+timeout=5 ; seconds
+[server.http.gzip]
+level=6
+[remote "origin"]
+url=git@example.com:a.git
+[remote "Up.Stream"]
+url=git@example.com:b.git
+[db.main.pool]
+size=10
+`
+
+func parseSubsections(ini *INI) {
+	ini.SetParseSection(true)
+	ini.SetSubsections(true)
+}
+
+func parseHierarchy(ini *INI) {
+	parseSubsections(ini)
+	ini.SetInlineComments(true)
+}
+
+func TestGitConfigSubsections(t *testing.T) {
+	ini := parseTestINI(t, hierarchyTestData, parseHierarchy)
+	v, ok := ini.SectionGet("remote.origin", "url")
+	assert.Equal(t, v, "git@example.com:a.git")
+	assert.Equal(t, ok, true)
+	v, _ = ini.SectionGet(`remote "origin"`, "url")
+	assert.Equal(t, v, "git@example.com:a.git")
+	v, _ = ini.Section("remote").Sub("Up.Stream").Get("url")
+	assert.Equal(t, v, "git@example.com:b.git")
+	assert.Equal(t, ini.HasSection(`remote  "origin"`), true)
+
+	// Write keeps the spelling
+	ini.SectionSet("remote.origin", "fetch", "+refs/heads/*")
+	out := writeString(t, ini)
+	assert.Equal(t, out[len(out)-len("[db.main.pool]\nsize=10\n"):], "[db.main.pool]\nsize=10\n")
+	assert.Equal(t, strings.Contains(out, "[remote \"origin\"]\nurl=git@example.com:a.git\nfetch=+refs/heads/*\n"), true)
+}
+
+func TestGitConfigSubsectionsCaseInsensitive(t *testing.T) {
+	ini := parseTestINI(t, "[Remote \"Origin\"]\nurl=a\n", withDialect(GitConfigDialect))
+	assert.Equal(t, ini.HasSection(`remote "Origin"`), true)
+	// As in git, the whole dotted name is case insensitive
+	assert.Equal(t, ini.HasSection("REMOTE.Origin"), false)
+	assert.Equal(t, ini.HasSection(`remote "origin"`), false)
+	assert.Equal(t, ini.Freeze().HasSection(`REMOTE "Origin"`), true)
+}
+
+func TestSubsectionsDisabled(t *testing.T) {
+	ini := parseTestINI(t, "[a \"b\"]\nx=1\n[a.b]\ny=2\n", parseSections)
+	assert.Equal(t, len(ini.GetAll()), 3)
+	v, _ := ini.SectionGet(`a "b"`, "x")
+	assert.Equal(t, v, "1")
+	assert.Equal(t, ini.HasKey("a.b", "x"), false)
+	assert.Equal(t, ini.ChildSections("a"), []string{"a.b"})
+
+	ini.SetSubsections(true)
+	assert.Equal(t, ini.Dialect().Subsections, true)
+}
+
+func TestSplitSubsection(t *testing.T) {
+	tests := []struct {
+		section, name, sub string
+		ok                 bool
+	}{
+		{`remote "origin"`, "remote", "origin", true},
+		{"branch\t\"a.b\"", "branch", "a.b", true},
+		{`a "b\"c\\d"`, "a", `b"c\d`, true},
+		{`a ""`, "a", "", true},
+		{`a "b"c"`, "", "", false},
+		{`a "b\"`, "", "", false},
+		{`"b"`, "", "", false},
+		{`a b"`, "", "", false},
+		{`a.b`, "", "", false},
+	}
+	for _, test := range tests {
+		name, sub, ok := splitSubsection(test.section)
+		assert.Equal(t, name, test.name, test.section)
+		assert.Equal(t, sub, test.sub, test.section)
+		assert.Equal(t, ok, test.ok, test.section)
+	}
+}
+
+func TestChildSections(t *testing.T) {
+	ini := parseTestINI(t, hierarchyTestData, parseHierarchy)
+	assert.Equal(t, ini.ChildSections(""), []string{"server", "remote", "db"})
+	assert.Equal(t, ini.ChildSections("server"), []string{"server.http"})
+	assert.Equal(t, ini.ChildSections("server.http"), []string{"server.http.gzip"})
+	assert.Equal(t, ini.ChildSections("remote"), []string{`remote "origin"`, `remote "Up.Stream"`})
+	assert.Equal(t, ini.ChildSections("remote.Up"), []string(nil))
+	assert.Equal(t, ini.ChildSections("db"), []string{"db.main"})
+	assert.Equal(t, ini.ChildSections("none"), []string(nil))
+
+	var names []string
+	for _, child := range ini.Section("db").Children() {
+		names = append(names, child.Name())
+		assert.Equal(t, child.Exists(), false)
+		assert.Equal(t, child.Children()[0].Name(), "db.main.pool")
+	}
+	assert.Equal(t, names, []string{"db.main"})
+}
+
+func TestSubtree(t *testing.T) {
+	ini := parseTestINI(t, hierarchyTestData, parseHierarchy)
+	sub := ini.Subtree("server")
+	assert.Equal(t, writeString(t, sub), "port=80\n[http]\ntimeout=5 ; seconds\n[http.gzip]\nlevel=6\n")
+	assert.Equal(t, sub.Line("", "port"), 3)
+	assert.Equal(t, sub.SectionLine("http"), 4)
+	assert.Equal(t, sub.InlineComment("http", "timeout"), "; seconds")
+
+	sub = ini.Section("remote").Subtree()
+	assert.Equal(t, writeString(t, sub), "[origin]\nurl=git@example.com:a.git\n[Up.Stream]\nurl=git@example.com:b.git\n")
+
+	sub = ini.Subtree("db")
+	level, ok := sub.SectionGetInt("main.pool", "size")
+	assert.Equal(t, level, 10)
+	assert.Equal(t, ok, true)
+
+	// The subtree is a copy
+	sub.SectionSet("main.pool", "size", "20")
+	v, _ := ini.SectionGet("db.main.pool", "size")
+	assert.Equal(t, v, "10")
+
+	assert.Equal(t, ini.Subtree("").Equal(ini), true)
+	assert.Equal(t, len(ini.Subtree("none").ChildSections("")), 0)
+}
+
+func TestUnmarshalNestedSections(t *testing.T) {
+	ini := parseTestINI(t, hierarchyTestData, parseHierarchy)
+	var config struct {
+		Name   string `ini:"name"`
+		Server struct {
+			Port int `ini:"port"`
+			HTTP struct {
+				Timeout int `ini:"timeout"`
+				Gzip    struct {
+					Level int `ini:"level"`
+				} `ini:"gzip"`
+			} `ini:"http"`
+		} `ini:"server"`
+		Remote struct {
+			Origin struct {
+				URL string `ini:"url" validate:"required"`
+			} `ini:"origin"`
+		} `ini:"remote"`
+	}
+	err := ini.Unmarshal(&config)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, config.Name, "app")
+	assert.Equal(t, config.Server.Port, 80)
+	assert.Equal(t, config.Server.HTTP.Timeout, 5)
+	assert.Equal(t, config.Server.HTTP.Gzip.Level, 6)
+	assert.Equal(t, config.Remote.Origin.URL, "git@example.com:a.git")
+}
@@ -30,7 +30,22 @@ const (
 //		ip=192.168.0.1
 //
 func LoadInheritedINI(filename string) (*INI, error) {
+	return loadInheritedINI(filename, New())
+}
+
+// LoadInheritedINIDialect is like LoadInheritedINI, but parses all the
+// INI files with the dialect d. If the dialect is case insensitive, so is
+// the inheritance.
+func LoadInheritedINIDialect(filename string, d Dialect) (*INI, error) {
+	settings := New()
+	settings.SetDialect(d)
+	return loadInheritedINI(filename, settings)
+}
+
+// loadInheritedINI loads the INI files with the parser settings of settings
+func loadInheritedINI(filename string, settings *INI) (*INI, error) {
 	ini := New()
+	settings.copySettings(ini)
 	err := ini.ParseFile(filename)
 	if err != nil {
 		return nil, err
@@ -42,23 +57,27 @@ func LoadInheritedINI(filename string) (*INI, error) {
 	}
 	
 	inherited = GetPathByRelativePath(filename, inherited)
-	inheritedINI, err := LoadInheritedINI(inherited)
+	inheritedINI, err := loadInheritedINI(inherited, settings)
 	if err != nil {
 		return nil, errors.New(err.Error() + " " + inherited)
 	}
 	
 	ini.Merge(inheritedINI, false)
+	ini.inherited = true
 	return ini, nil
 }
 
 // Merge merges the data in another INI (from) to this INI (ini), and
-// from INI will not be changed
+// from INI will not be changed. The names are compared as this INI does,
+// see SetCaseInsensitive.
 func (ini *INI) Merge(from *INI, override bool) {
-	for section, kv := range from.sections {
-		for key, value := range kv {
-			_, found := ini.SectionGet(section, key)
+	for _, section := range from.sectionList() {
+		kv := from.sections[section]
+		name := from.spellSection(section)
+		for _, key := range from.keyList(section) {
+			_, found := ini.SectionGet(name, from.spellKey(section, key))
 			if override || !found {
-				ini.SectionSet(section, key, value)
+				ini.SectionSet(name, from.spellKey(section, key), kv[key])
 			}
 		}
 	}
@@ -6,11 +6,11 @@ package goini
 
 import (
     "bufio"
-    "bytes"
     "errors"
     "io"
     "os"
     "log"
+    "sort"
     "strconv"
 )
 
@@ -26,18 +26,48 @@ const (
     DefaultKeyValueSeparator = "="
 )
 
+type lineKey struct {
+    section string
+    key     string
+}
+
 type INI struct {
     sections     SectionMap
+    sectionNames []string            // The sections in document order
+    keyNames     map[string][]string // The keys of every section in document order
+    sectionLines map[string]int      // The line numbers of the parsed sections
+    keyLines     map[lineKey]int     // The line numbers of the parsed keys
+    foldSections bool                // Whether the section names are case insensitive
+    foldKeys     bool                // Whether the keys are case insensitive
+    subsections  bool                // Whether the git config style sections are stored as dotted names
+    sectionSpelling map[string]string  // The spelling of the case folded section names
+    keySpelling     map[lineKey]string // The spelling of the case folded keys
+    comments        map[lineKey]string // The inline comments of the keys
+    free            []Kvmap            // The maps of the sections cleared by Reset, reused by newSection
+    freeKeys        [][]string         // The key lists of the sections cleared by Reset, reused by newSection
+    interned        map[string]string  // The section names and keys which are parsed, see internName
+    zeroCopy        bool               // Whether the parsed values refer to the data given to Parse
+    borrowed        bool               // Whether some values refer to the data given to Parse
+    cache           *parseCache        // The dialect of the last parse
     lineSep      string
     kvSep        string
     parseSection bool
     skipCommits  bool
     trimQuotes   bool // Whether to trim quotation marks. default is false.
+    inlineComments bool // Whether to strip the inline comments after the values
+    dialect      *Dialect // The dialect set by SetDialect, which replaces parseSection, skipCommits and trimQuotes
+    filename     string // The file loaded by ParseFile or LoadInheritedINI, used by Reload
+    inherited    bool   // Whether filename is loaded by LoadInheritedINI
+    subs         subscribers
+    aliases      aliases
 }
 
 func New() *INI {
     ini := &INI{
         sections:     make(SectionMap),
+        keyNames:     make(map[string][]string),
+        sectionLines: make(map[string]int),
+        keyLines:     make(map[lineKey]int),
         lineSep:      DefaultLineSeparator,
         kvSep:        DefaultKeyValueSeparator,
         parseSection: false,
@@ -53,46 +83,117 @@ func (ini *INI) ParseFile(filename string) error {
     if err != nil {
         return err
     }
+    ini.filename = filename
+    if ini.dialect != nil {
+        return ini.parseINI(contents, "", "")
+    }
     ini.parseSection = true
     ini.skipCommits = true
     return ini.parseINI(contents, DefaultLineSeparator, DefaultKeyValueSeparator)
 }
 
+// Reload parses the file loaded by ParseFile (or LoadInheritedINI) again and
+// replaces the data hold by INI with the new contents. The subscribers are
+// notified of every key which has been added, changed or removed.
+// If the file can not be parsed, INI is left untouched.
+func (ini *INI) Reload() error {
+    if ini.filename == "" {
+        return errors.New("Nothing to reload : the INI is not loaded from a file")
+    }
+
+    // The file is parsed with the current settings of this INI
+    var fresh *INI
+    var err error
+    if ini.inherited {
+        fresh, err = loadInheritedINI(ini.filename, ini)
+    } else {
+        fresh = New()
+        ini.copySettings(fresh)
+        err = fresh.ParseFile(ini.filename)
+    }
+    if err != nil {
+        return err
+    }
+
+    changes := diffSectionMap(ini.sections, fresh.sections)
+    ini.swapData(fresh)
+    ini.notify(changes)
+    return nil
+}
+
 // Parse parses the data to store the data in the INI
 // A successful call returns err == nil
 func (ini *INI) Parse(data []byte, lineSep, kvSep string) error {
     return ini.parseINI(data, lineSep, kvSep)
 }
 
-// ParseFrom reads all the data from reader r and parse the contents to store the data in the INI
+// ParseFrom reads the data from reader r incrementally and parse the contents to store the data in the INI
+// The size of the lines is not limited, see Parser for a limited one.
 // A successful call returns err == nil
 func (ini *INI) ParseFrom(r io.Reader, lineSep, kvSep string) error {
-    data, err := io.ReadAll(r)
-    if err == nil {
-        return ini.parseINI(data, lineSep, kvSep)
-    }
-    return err
+    return ini.parseReader(r, lineSep, kvSep)
 }
 
-// Reset clears all the data hold by INI
+// Reset clears all the data hold by INI. The maps returned by GetAll and
+// GetKvmap are left as they are, unless SetZeroCopy is set : then their
+// memory is reused by the next Parse, so they must not be used after Reset.
 func (ini *INI) Reset() {
-    ini.sections = make(SectionMap)
-    //FIXME effective optimize
+    if ini.zeroCopy {
+        for section, kv := range ini.sections {
+            clear(kv)
+            ini.free = append(ini.free, kv)
+            ini.freeKeys = append(ini.freeKeys, ini.keyNames[section][:0])
+        }
+        clear(ini.sections)
+    } else {
+        ini.sections = make(SectionMap)
+    }
+    ini.borrowed = false
+    ini.sectionNames = ini.sectionNames[:0]
+    clear(ini.keyNames)
+    clear(ini.sectionLines)
+    clear(ini.keyLines)
+    clear(ini.sectionSpelling)
+    clear(ini.keySpelling)
+    clear(ini.comments)
 }
 
 // SetSkipCommits sets INI.skipCommits whether to skip commits when parsing
 func (ini *INI) SetSkipCommits(skipCommits bool) {
     ini.skipCommits = skipCommits
+    ini.cache = nil
+    if ini.dialect != nil {
+        ini.dialect.CommentPrefixes = nil
+        if skipCommits {
+            ini.dialect.CommentPrefixes = legacyComments
+        }
+    }
 }
 
 // SetParseSection sets INI.parseSection whether to process the INI section when parsing
 func (ini *INI) SetParseSection(parseSection bool) {
     ini.parseSection = parseSection
+    ini.cache = nil
+    if ini.dialect != nil {
+        ini.dialect.SectionStart, ini.dialect.SectionEnd = "", ""
+        if parseSection {
+            ini.dialect.SectionStart, ini.dialect.SectionEnd = "[", "]"
+        }
+    }
 }
 
-// SetTrimQuotes sets INI.trimQuotes whether to trim quotation marks of the value when parsing
+// SetTrimQuotes sets INI.trimQuotes whether to trim quotation marks of the value when parsing.
+// The escape sequences of a double quoted value are replaced, and Write quotes the values
+// which could not be parsed back otherwise. See Dialect.Quotes.
 func (ini *INI) SetTrimQuotes(v bool) {
     ini.trimQuotes = v
+    ini.cache = nil
+    if ini.dialect != nil {
+        ini.dialect.Quotes = ""
+        if v {
+            ini.dialect.Quotes = `"'`
+        }
+    }
 }
 
 // Get looks up a value for a key in the default section
@@ -122,9 +223,13 @@ func (ini *INI) GetBool(key string) (bool, bool) {
 // SectionGet looks up a value for a key in a section
 // and returns that value, along with a boolean result similar to a map lookup.
 func (ini *INI) SectionGet(section, key string) (value string, ok bool) {
+    section, key = ini.sectionName(section), ini.keyName(key)
     if s := ini.sections[section]; s != nil {
         value, ok = s[key]
     }
+    if !ok && ini.aliases.list != nil {
+        return ini.resolveAlias(section, key)
+    }
     return
 }
 
@@ -158,26 +263,52 @@ func (ini *INI) SectionGetFloat(section, key string) (float64, bool) {
 func (ini *INI) SectionGetBool(section, key string) (bool, bool) {
     v, ok := ini.SectionGet(section, key)
     if ok {
-        switch v {
-        case "1", "t", "T", "true", "TRUE", "True", "on", "ON", "On", "yes", "YES", "Yes":
-            return true, true
-        case "0", "f", "F", "false", "FALSE", "False", "off", "OFF", "Off", "no", "NO", "No":
-            return false, true
-        }
+        return parseBool(v)
     }
 
     return false, false
 }
 
+// parseBool parses a boolean value. See GetBool for more detail
+func parseBool(v string) (value bool, ok bool) {
+    switch v {
+    case "1", "t", "T", "true", "TRUE", "True", "on", "ON", "On", "yes", "YES", "Yes":
+        return true, true
+    case "0", "f", "F", "false", "FALSE", "False", "off", "OFF", "Off", "no", "NO", "No":
+        return false, true
+    }
+    return false, false
+}
+
 // GetKvmap gets all keys under section as a Kvmap (map[string]string).
 // The first return value will get the value that corresponds to the key
 // (or the map’s value type’s zero value if the key isn’t present),
 // and the second will get true(or false if the key isn’t present).
 func (ini *INI) GetKvmap(section string) (kvmap Kvmap, ok bool) {
-    kvmap, ok = ini.sections[section]
+    kvmap, ok = ini.sections[ini.sectionName(section)]
     return kvmap, ok
 }
 
+// Line returns the line number of the key in section in the parsed data,
+// or 0 if the key does not exist or has not been parsed from data.
+// The lines are separated by the line separator and counted from 1.
+func (ini *INI) Line(section, key string) int {
+    if _, ok := ini.SectionGet(section, key); !ok {
+        return 0
+    }
+    return ini.keyLines[lineKey{ini.sectionName(section), ini.keyName(key)}]
+}
+
+// SectionLine returns the line number of the header of section in the
+// parsed data, or 0 if the section does not exist or has no header.
+func (ini *INI) SectionLine(section string) int {
+    section = ini.sectionName(section)
+    if _, ok := ini.sections[section]; !ok {
+        return 0
+    }
+    return ini.sectionLines[section]
+}
+
 // GetAll gets the section map and its key/value pairs.
 func (ini *INI) GetAll() SectionMap {
     return ini.sections
@@ -232,93 +363,317 @@ func (ini *INI) SectionSetBool(section, key string, value bool) {
 // SectionSet stores the section/key/value triple to this INI,
 // creating it if it wasn't already present.
 func (ini *INI) SectionSet(section, key, value string) {
+    sectionSpelling, keySpelling := section, key
+    section, key = ini.sectionName(section), ini.keyName(key)
     kvmap, ok := ini.sections[section]
     if !ok {
-        kvmap = make(Kvmap)
-        ini.sections[section] = kvmap
+        kvmap = ini.newSection(section)
+        ini.recordSectionSpelling(section, sectionSpelling)
     }
+    old, found := kvmap[key]
     kvmap[key] = value
+    if !found {
+        ini.keyNames[section] = append(ini.keyNames[section], key)
+        ini.recordKeySpelling(section, key, keySpelling)
+    }
+    if !found || old != value {
+        ini.notify([]change{{section, key, old, value, false}})
+    }
 }
 
 // Delete deletes the key in given section.
 func (ini *INI) Delete(section, key string) {
-    kvmap, ok := ini.GetKvmap(section)
+    section, key = ini.sectionName(section), ini.keyName(key)
+    kvmap, ok := ini.sections[section]
     if ok {
+        old, found := kvmap[key]
         delete(kvmap, key)
+        if found {
+            ini.keyNames[section] = removeName(ini.keyNames[section], key)
+            delete(ini.keyLines, lineKey{section, key})
+            delete(ini.comments, lineKey{section, key})
+            ini.notify([]change{{section, key, old, "", true}})
+        }
     }
 }
 
 // Write tries to write the INI data into an output.
+// It fails without writing anything if the dialect has no sections,
+// like PropertiesDialect, and a section other than the default one exists.
 func (ini *INI) Write(w io.Writer) error {
+    start, end := "[", "]"
+    if ini.dialect != nil && ini.dialect.SectionStart != "" {
+        start, end = ini.dialect.SectionStart, ini.dialect.SectionEnd
+    } else if ini.dialect != nil {
+        for section := range ini.sections {
+            if section != DefaultSection {
+                return errors.New("The dialect has no sections but found section " + ini.spellSection(section))
+            }
+        }
+    }
+
     buf := bufio.NewWriter(w)
 
-    //write the default section first
-    if kv, ok := ini.GetKvmap(DefaultSection); ok {
-        ini.write(kv, buf)
-    }
+    // The dialect tells how to quote and escape the values
+    d := ini.parseDialect(ini.lineSep, ini.kvSep)
 
-    for section, kv := range ini.sections {
-        if section == DefaultSection {
-            continue
+    // the default section is always the first one
+    for _, section := range ini.sectionList() {
+        if section != DefaultSection {
+            buf.WriteString(start + ini.spellSection(section) + end + ini.lineSep)
         }
-        buf.WriteString("[" + section + "]" + ini.lineSep)
-        ini.write(kv, buf)
+        ini.write(section, d, buf)
     }
     return buf.Flush()
 }
 
 //////////////////////////////////////////////////////////////////////////
-func (ini *INI) write(kv Kvmap, buf *bufio.Writer) {
-    for k, v := range kv {
-        buf.WriteString(k)
+
+// diffSectionMap returns the changes which turn the data of from into to,
+// sorted by section and key.
+func diffSectionMap(from, to SectionMap) []change {
+    var changes []change
+    for section, kv := range from {
+        for key, old := range kv {
+            value, found := to[section][key]
+            if !found {
+                changes = append(changes, change{section, key, old, "", true})
+            } else if value != old {
+                changes = append(changes, change{section, key, old, value, false})
+            }
+        }
+    }
+    for section, kv := range to {
+        for key, value := range kv {
+            if _, found := from[section][key]; !found {
+                changes = append(changes, change{section, key, "", value, false})
+            }
+        }
+    }
+    sort.Slice(changes, func(i, j int) bool {
+        if changes[i].section != changes[j].section {
+            return changes[i].section < changes[j].section
+        }
+        return changes[i].key < changes[j].key
+    })
+    return changes
+}
+
+func (ini *INI) write(section string, d *Dialect, buf *bufio.Writer) {
+    kv := ini.sections[section]
+    for _, k := range ini.keyList(section) {
+        buf.WriteString(d.formatKey(ini.spellKey(section, k)))
         buf.WriteString(ini.kvSep)
-        buf.WriteString(v)
+        buf.WriteString(d.formatValue(kv[k]))
+        if comment, ok := ini.comments[lineKey{section, k}]; ok {
+            buf.WriteString(" " + comment)
+        }
         buf.WriteString(ini.lineSep)
     }
 }
 
-func (ini *INI) parseINI(data []byte, lineSep, kvSep string) error {
-    ini.lineSep = lineSep
-    ini.kvSep = kvSep
+// newSection creates an empty section, which is appended to the document
+// if it did not exist. The keys of an existing section are cleared.
+func (ini *INI) newSection(section string) Kvmap {
+    kvmap, ok := ini.sections[section]
+    if ok {
+        clear(kvmap)
+    } else {
+        ini.sectionNames = append(ini.sectionNames, section)
+        if n := len(ini.free); n > 0 {
+            kvmap = ini.free[n-1]
+            ini.free = ini.free[:n-1]
+        } else {
+            kvmap = make(Kvmap)
+        }
+        ini.sections[section] = kvmap
+        if n := len(ini.freeKeys); n > 0 && ini.keyNames[section] == nil {
+            ini.keyNames[section] = ini.freeKeys[n-1]
+            ini.freeKeys = ini.freeKeys[:n-1]
+        }
+    }
+    ini.keyNames[section] = ini.keyNames[section][:0]
+    return kvmap
+}
 
-    // Insert the default section
-    var section string
-    kvmap := make(Kvmap)
-    ini.sections[section] = kvmap
-
-    lines := bytes.Split(data, []byte(lineSep))
-    for _, line := range lines {
-        line = bytes.TrimSpace(line)
-        size := len(line)
-        if size == 0 {
-            // Skip blank lines
-            continue
+// storeSection creates the empty section spelled spelling while loading
+// data, and returns its stored name and its keys
+func (ini *INI) storeSection(spelling string) (string, Kvmap) {
+    section := ini.sectionName(spelling)
+    kvmap := ini.newSection(section)
+    ini.recordSectionSpelling(section, spelling)
+    return section, kvmap
+}
+
+// storeKey stores the key spelled spelling and its value while loading
+// data into the stored section, of which kvmap holds the keys
+func (ini *INI) storeKey(section string, kvmap Kvmap, spelling, value string) {
+    key := ini.keyName(spelling)
+    if _, found := kvmap[key]; !found {
+        ini.keyNames[section] = append(ini.keyNames[section], key)
+        ini.recordKeySpelling(section, key, spelling)
+    }
+    kvmap[key] = value
+}
+
+// sectionList returns the names of all the sections in document order.
+// The default section is always the first one. Sections which are added
+// to the SectionMap directly come last, sorted by name.
+func (ini *INI) sectionList() []string {
+    names := make([]string, 0, len(ini.sections))
+    seen := make(map[string]bool, len(ini.sections))
+    if _, ok := ini.sections[DefaultSection]; ok {
+        names = append(names, DefaultSection)
+        seen[DefaultSection] = true
+    }
+    for _, section := range ini.sectionNames {
+        if _, ok := ini.sections[section]; ok && !seen[section] {
+            names = append(names, section)
+            seen[section] = true
         }
-        if ini.skipCommits && line[0] == ';' || line[0] == '#' {
-            // Skip comments
-            continue
+    }
+    return appendUnseen(names, seen, ini.sections)
+}
+
+// keyList returns the keys of section in document order. Keys which are
+// added to the Kvmap directly come last, sorted by name.
+func (ini *INI) keyList(section string) []string {
+    kv := ini.sections[section]
+    names := make([]string, 0, len(kv))
+    seen := make(map[string]bool, len(kv))
+    for _, key := range ini.keyNames[section] {
+        if _, ok := kv[key]; ok && !seen[key] {
+            names = append(names, key)
+            seen[key] = true
         }
-        if ini.parseSection && line[0] == '[' && line[size-1] == ']' {
-            // Parse INI-Section
-            section = string(line[1 : size-1])
-            kvmap = make(Kvmap)
-            ini.sections[section] = kvmap
-            continue
+    }
+    return appendUnseen(names, seen, kv)
+}
+
+func appendUnseen[V any](names []string, seen map[string]bool, m map[string]V) []string {
+    if len(names) == len(m) {
+        return names
+    }
+    n := len(names)
+    for name := range m {
+        if !seen[name] {
+            names = append(names, name)
+        }
+    }
+    sort.Strings(names[n:])
+    return names
+}
+
+// removeName removes the first name from names
+func removeName(names []string, name string) []string {
+    for i, n := range names {
+        if n == name {
+            return append(names[:i:i], names[i+1:]...)
+        }
+    }
+    return names
+}
+
+// copyData returns a new INI holding a deep copy of the data of this INI
+func (ini *INI) copyData() *INI {
+    c := New()
+    c.foldSections = ini.foldSections
+    c.foldKeys = ini.foldKeys
+    c.subsections = ini.subsections
+    c.sectionNames = append([]string(nil), ini.sectionNames...)
+    for section, spelling := range ini.sectionSpelling {
+        c.recordSectionSpelling(section, spelling)
+    }
+    for k, spelling := range ini.keySpelling {
+        c.recordKeySpelling(k.section, k.key, spelling)
+    }
+    for section, line := range ini.sectionLines {
+        c.sectionLines[section] = line
+    }
+    for k, line := range ini.keyLines {
+        c.keyLines[k] = line
+    }
+    for k, comment := range ini.comments {
+        c.setComment(k.section, k.key, comment)
+    }
+    for section, kv := range ini.sections {
+        ckv := make(Kvmap, len(kv))
+        for k, v := range kv {
+            ckv[k] = ini.ownedValue(v)
         }
+        c.sections[section] = ckv
+        c.keyNames[section] = ini.keyList(section)
+    }
+    return c
+}
 
-        pos := bytes.Index(line, []byte(kvSep))
-        if pos < 0 {
-            // ERROR happened when passing
-            err := errors.New("Came accross an error : " + string(line) + " is NOT a valid key/value pair")
+// swapData replaces the data hold by INI with the data of from
+func (ini *INI) swapData(from *INI) {
+    ini.sections = from.sections
+    ini.sectionNames = from.sectionNames
+    ini.keyNames = from.keyNames
+    ini.sectionLines = from.sectionLines
+    ini.keyLines = from.keyLines
+    ini.sectionSpelling = from.sectionSpelling
+    ini.keySpelling = from.keySpelling
+    ini.comments = from.comments
+}
+
+
+func (ini *INI) parseINI(data []byte, lineSep, kvSep string) error {
+    d, sep := ini.cachedParseDialect(lineSep, kvSep)
+    p := Parser{d: d, data: data, sep: sep}
+    return ini.parse(&p, ini.zeroCopy)
+}
+
+// parseReader parses the data read from r incrementally
+func (ini *INI) parseReader(r io.Reader, lineSep, kvSep string) error {
+    return ini.parse(newParser(r, ini.parseDialect(lineSep, kvSep)), false)
+}
+
+// parse stores the data parsed by p. If zeroCopy is true, the values refer
+// to the data parsed, instead of copies of them.
+func (ini *INI) parse(p *Parser, zeroCopy bool) error {
+    ini.lineSep = p.d.LineSeparator
+    ini.kvSep = p.d.KeyValueSeparators[0]
+
+    // Insert the default section
+    var section string
+    kvmap := ini.newSection(section)
+
+    for {
+        t, err := p.scan()
+        if err == io.EOF {
+            return nil
+        }
+        if err != nil {
             return err
         }
 
-        k := bytes.TrimSpace(line[0:pos])
-        v := bytes.TrimSpace(line[pos+len(kvSep):])
-        if ini.trimQuotes {
-            v = bytes.Trim(v, "'\"")
+        switch t.typ {
+        case SectionEvent:
+            spelling := ini.internName(t.name)
+            section = ini.sectionName(spelling)
+            kvmap = ini.newSection(section)
+            ini.recordSectionSpelling(section, spelling)
+            ini.sectionLines[section] = t.line
+        case KeyValueEvent:
+            spelling := ini.internName(t.name)
+            key := ini.keyName(spelling)
+            if _, found := kvmap[key]; !found {
+                ini.keyNames[section] = append(ini.keyNames[section], key)
+                ini.recordKeySpelling(section, key, spelling)
+            }
+            if zeroCopy {
+                kvmap[key] = unsafeString(t.value)
+                ini.borrowed = true
+            } else {
+                kvmap[key] = string(t.value)
+            }
+            ini.keyLines[lineKey{section, key}] = t.line
+            if len(t.comment) > 0 || ini.comments != nil {
+                ini.setComment(section, key, string(t.comment))
+            }
         }
-        kvmap[string(k)] = string(v)
     }
-    return nil
 }
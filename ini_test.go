@@ -736,17 +736,56 @@ func TestQuoteValue(t *testing.T) {
 func Benchmark1(b *testing.B) {
 	raw := []byte("a:1||b:True||c:true||||d:off||e:on||f:false||g:0||||||")
 	ini := New()
+	b.ReportAllocs()
 
 	for i := 0; i < b.N; i++ {
 		ini.Parse(raw, "||", ":")
 	}
 }
 
+// run this by command : go test -test.bench="Benchmark1Reset"
+func Benchmark1Reset(b *testing.B) {
+	raw := []byte("a:1||b:True||c:true||||d:off||e:on||f:false||g:0||||||")
+	ini := New()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		ini.Reset()
+		ini.Parse(raw, "||", ":")
+	}
+}
+
+// run this by command : go test -test.bench="Benchmark1ZeroCopy"
+func Benchmark1ZeroCopy(b *testing.B) {
+	raw := []byte("a:1||b:True||c:true||||d:off||e:on||f:false||g:0||||||")
+	ini := New()
+	ini.SetZeroCopy(true)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		ini.Reset()
+		ini.Parse(raw, "||", ":")
+	}
+}
+
+// run this by command : go test -test.bench="Benchmark1New"
+// It is the way to parse before Reset kept the memory
+func Benchmark1New(b *testing.B) {
+	raw := []byte("a:1||b:True||c:true||||d:off||e:on||f:false||g:0||||||")
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		ini := New()
+		ini.Parse(raw, "||", ":")
+	}
+}
+
 // run this by command : go test -test.bench="Benchmark2"
 func Benchmark2(b *testing.B) {
 	raw := []byte("a:1||b:True||c:true||||d:off||e:on||f:false||g:0||||||")
 	ini := New()
 	ini.Parse(raw, "||", ":")
+	b.ReportAllocs()
 
 	for i := 0; i < b.N; i++ {
 		v, _ := ini.GetBool("f")
@@ -0,0 +1,80 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+// Entry is a key/value pair of a section, yielded by All
+type Entry struct {
+	Section string
+	Key     string
+	Value   string
+}
+
+// The iterators below have the types of iter.Seq and iter.Seq2, so they
+// can be used with range over func, e.g. :
+//
+//	for section := range ini.Sections() {
+//		for key, value := range ini.Pairs(section) {
+//			...
+//		}
+//	}
+//
+// They yield the names with the spelling they are written with, in
+// document order. The INI may be modified while iterating: the keys
+// deleted are skipped, and the keys added may or may not be yielded.
+
+// Sections returns an iterator over the names of the sections. The default
+// section is the first one, and is skipped if it is empty.
+func (ini *INI) Sections() func(yield func(section string) bool) {
+	return func(yield func(string) bool) {
+		for _, section := range ini.sectionList() {
+			if section == DefaultSection && len(ini.sections[section]) == 0 {
+				continue
+			}
+			if _, ok := ini.sections[section]; !ok {
+				continue
+			}
+			if !yield(ini.spellSection(section)) {
+				return
+			}
+		}
+	}
+}
+
+// Pairs returns an iterator over the key/value pairs of section
+func (ini *INI) Pairs(section string) func(yield func(key, value string) bool) {
+	return func(yield func(string, string) bool) {
+		ini.pairs(ini.sectionName(section), yield)
+	}
+}
+
+// All returns an iterator over the key/value pairs of all the sections
+func (ini *INI) All() func(yield func(Entry) bool) {
+	return func(yield func(Entry) bool) {
+		for _, section := range ini.sectionList() {
+			spelling := ini.spellSection(section)
+			ok := ini.pairs(section, func(key, value string) bool {
+				return yield(Entry{spelling, key, value})
+			})
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// pairs calls yield for every key/value pair of the stored section, and
+// returns false if yield returns false
+func (ini *INI) pairs(section string, yield func(key, value string) bool) bool {
+	for _, key := range ini.keyList(section) {
+		value, ok := ini.sections[section][key]
+		if !ok {
+			continue
+		}
+		if !yield(ini.spellKey(section, key), value) {
+			return false
+		}
+	}
+	return true
+}
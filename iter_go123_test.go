@@ -0,0 +1,41 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.23
+
+package goini
+
+import (
+	"iter"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+// The iterators have the types of the iter package
+var (
+	_ iter.Seq[string]          = (*INI)(nil).Sections()
+	_ iter.Seq2[string, string] = (*INI)(nil).Pairs("")
+	_ iter.Seq[Entry]           = (*INI)(nil).All()
+)
+
+func TestRangeOverFunc(t *testing.T) {
+	ini := parseTestINI(t, sectionTestData, parseSections)
+	var got []string
+	for section := range ini.Sections() {
+		for key, value := range ini.Pairs(section) {
+			got = append(got, section+"."+key+"="+value)
+		}
+	}
+	assert.Equal(t, got, []string{".z=1", ".a=2", "sss.c=3", "sss.b=4", "ddd.age=30"})
+
+	n := 0
+	for range ini.All() {
+		n++
+		if n == 2 {
+			break
+		}
+	}
+	assert.Equal(t, n, 2)
+}
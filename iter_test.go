@@ -0,0 +1,110 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func collectSections(ini *INI) []string {
+	var names []string
+	ini.Sections()(func(section string) bool {
+		names = append(names, section)
+		return true
+	})
+	return names
+}
+
+func TestSections(t *testing.T) {
+	ini := parseTestINI(t, sectionTestData, parseSections)
+	assert.Equal(t, collectSections(ini), []string{"", "sss", "ddd"})
+
+	ini.Delete("", "z")
+	ini.Delete("", "a")
+	assert.Equal(t, collectSections(ini), []string{"sss", "ddd"})
+
+	var names []string
+	ini.Sections()(func(section string) bool {
+		names = append(names, section)
+		return false
+	})
+	assert.Equal(t, names, []string{"sss"})
+}
+
+func TestPairs(t *testing.T) {
+	ini := parseTestINI(t, sectionTestData, parseSections)
+	var got []string
+	ini.Pairs("sss")(func(key, value string) bool {
+		got = append(got, key+"="+value)
+		return true
+	})
+	assert.Equal(t, got, []string{"c=3", "b=4"})
+
+	got = nil
+	ini.Pairs("none")(func(key, value string) bool {
+		got = append(got, key+"="+value)
+		return true
+	})
+	assert.Equal(t, len(got), 0)
+}
+
+func TestAll(t *testing.T) {
+	ini := parseTestINI(t, sectionTestData, parseSections)
+	var got []Entry
+	ini.All()(func(e Entry) bool {
+		got = append(got, e)
+		return true
+	})
+	assert.Equal(t, got, []Entry{
+		{"", "z", "1"}, {"", "a", "2"},
+		{"sss", "c", "3"}, {"sss", "b", "4"},
+		{"ddd", "age", "30"},
+	})
+
+	got = nil
+	ini.All()(func(e Entry) bool {
+		got = append(got, e)
+		return len(got) < 3
+	})
+	assert.Equal(t, len(got), 3)
+}
+
+func TestIterateWhileModifying(t *testing.T) {
+	ini := parseTestINI(t, sectionTestData, parseSections)
+	var got []string
+	ini.All()(func(e Entry) bool {
+		got = append(got, e.Key)
+		if e.Key == "z" {
+			ini.Delete("", "a")
+			ini.DeleteSection("sss")
+			ini.SectionSet("ddd", "new", "1")
+		}
+		return true
+	})
+	assert.Equal(t, got, []string{"z", "age", "new"})
+}
+
+func TestIterateSpelling(t *testing.T) {
+	ini := parseTestINI(t, foldTestData, caseInsensitive(true, true))
+	var got []Entry
+	ini.All()(func(e Entry) bool {
+		got = append(got, e)
+		return true
+	})
+	assert.Equal(t, got, []Entry{
+		{"", "Name", "goini"},
+		{"Server", "Host", "localhost"}, {"Server", "Port", "8080"},
+	})
+	assert.Equal(t, collectSections(ini), []string{"", "Server"})
+
+	var keys []string
+	ini.Pairs("SERVER")(func(key, value string) bool {
+		keys = append(keys, key)
+		return true
+	})
+	assert.Equal(t, keys, []string{"Host", "Port"})
+}
@@ -0,0 +1,281 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// JSONOptions changes how an INI is converted to and from JSON. The zero
+// value is used by MarshalJSON and UnmarshalJSON.
+type JSONOptions struct {
+	// DefaultSection is the name of the JSON object holding the keys of
+	// the default section, which are at the top level if it is empty
+	DefaultSection string
+
+	// InferTypes writes the values which are numbers, true or false as
+	// JSON numbers and booleans, and the values holding commas as arrays
+	// of the comma separated items, instead of strings. It does not round
+	// trip : the whitespace around the items and the empty items are lost,
+	// so "x, y" is written as ["x","y"] and parsed back as "x,y".
+	InferTypes bool
+
+	// Indent indents every nested level of the JSON with Indent
+	Indent string
+}
+
+// MarshalJSON implements json.Marshaler. See WriteJSON.
+func (ini *INI) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := ini.WriteJSON(&buf, JSONOptions{}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. See ParseJSON.
+func (ini *INI) UnmarshalJSON(data []byte) error {
+	return ini.ParseJSON(data, JSONOptions{})
+}
+
+// WriteJSON writes this INI as a JSON object, which maps the names of the
+// sections to the objects of their key/value pairs, in document order,
+// e.g. :
+//
+//	{"product":"goini","server":{"port":"8080"}}
+//
+// The subsections are written as the sections with dotted names. It fails
+// if a key of the default section has the name of a section.
+func (ini *INI) WriteJSON(w io.Writer, opts JSONOptions) error {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	n := 0
+	member := func(name string) {
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+		n++
+		writeJSONString(&buf, name)
+		buf.WriteByte(':')
+	}
+
+	sections := ini.nonEmptySections()
+	names := make(map[string]bool)
+	for _, section := range sections {
+		names[ini.spellSection(section)] = true
+	}
+	if opts.DefaultSection != "" && names[opts.DefaultSection] {
+		return errors.New("Section [" + opts.DefaultSection + "] collides with the default section")
+	}
+
+	var err error
+	for _, section := range sections {
+		if section == DefaultSection && opts.DefaultSection == "" {
+			// The keys of the default section are members of the top level
+			ini.pairs(section, func(key, value string) bool {
+				if names[key] {
+					err = errors.New("Key " + key + " of the default section collides with section [" + key + "]")
+					return false
+				}
+				member(key)
+				writeJSONValue(&buf, value, opts.InferTypes)
+				return true
+			})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if section == DefaultSection {
+			member(opts.DefaultSection)
+		} else {
+			member(ini.spellSection(section))
+		}
+		buf.WriteByte('{')
+		i := 0
+		ini.pairs(section, func(key, value string) bool {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			i++
+			writeJSONString(&buf, key)
+			buf.WriteByte(':')
+			writeJSONValue(&buf, value, opts.InferTypes)
+			return true
+		})
+		buf.WriteByte('}')
+	}
+	buf.WriteByte('}')
+
+	data := buf.Bytes()
+	if opts.Indent != "" {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, data, "", opts.Indent); err != nil {
+			return err
+		}
+		indented.WriteByte('\n')
+		data = indented.Bytes()
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// ParseJSON parses the JSON object written by WriteJSON to store the data
+// in the INI. As Parse does, it replaces the sections it holds. The numbers
+// and the booleans are stored with their JSON spelling, null is stored as
+// the empty value, and an array is stored as the comma separated list of
+// its items. An object nested in a section is its subsection.
+func (ini *INI) ParseJSON(data []byte, opts JSONOptions) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if t != json.Delim('{') {
+		return errors.New("The JSON of an INI must be an object")
+	}
+	if err := parseJSONObject(dec, newLoader(ini), DefaultSection, opts); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("Unexpected data after the JSON object")
+	}
+	return nil
+}
+
+// parseJSONObject stores the members of the JSON object in section, after
+// its opening brace is read. The members of the objects which map to the
+// same section are merged.
+func parseJSONObject(dec *json.Decoder, l *loader, section string, opts JSONOptions) error {
+	stored, kvmap := l.section(section, 0)
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key := t.(string)
+
+		t, err = dec.Token()
+		if err != nil {
+			return err
+		}
+		if t == json.Delim('{') {
+			sub := key
+			if section != DefaultSection {
+				sub = section + SubsectionSeparator + key
+			} else if key == opts.DefaultSection {
+				sub = DefaultSection
+			}
+			if err := parseJSONObject(dec, l, sub, opts); err != nil {
+				return err
+			}
+			continue
+		}
+
+		value, err := jsonValue(dec, t)
+		if err != nil {
+			return errors.New("Bad value of key " + key + " : " + err.Error())
+		}
+		l.ini.storeKey(stored, kvmap, key, value)
+	}
+	_, err := dec.Token() // The closing brace
+	return err
+}
+
+// jsonValue returns the value of the JSON token t, which is not an object
+func jsonValue(dec *json.Decoder, t json.Token) (string, error) {
+	switch v := t.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case nil:
+		return "", nil
+	case json.Delim:
+		if v != '[' {
+			break
+		}
+		var items []string
+		for dec.More() {
+			t, err := dec.Token()
+			if err != nil {
+				return "", err
+			}
+			if d, ok := t.(json.Delim); ok {
+				return "", errors.New("an array may only hold strings, numbers and booleans but found " + d.String())
+			}
+			item, _ := jsonValue(dec, t)
+			items = append(items, item)
+		}
+		_, err := dec.Token() // The closing bracket
+		return strings.Join(items, ","), err
+	}
+	return "", errors.New("unexpected JSON token")
+}
+
+// writeJSONValue writes value as a JSON string, or as a JSON number,
+// boolean or array if infer is true and it looks like one
+func writeJSONValue(buf *bytes.Buffer, value string, infer bool) {
+	if !infer {
+		writeJSONString(buf, value)
+		return
+	}
+	if !strings.Contains(value, ",") {
+		writeJSONScalar(buf, value)
+		return
+	}
+	buf.WriteByte('[')
+	n := 0
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+		n++
+		writeJSONScalar(buf, item)
+	}
+	buf.WriteByte(']')
+}
+
+// writeJSONScalar writes value as a JSON number or boolean if it is one,
+// or else as a JSON string
+func writeJSONScalar(buf *bytes.Buffer, value string) {
+	if value == "true" || value == "false" || isJSONNumber(value) {
+		buf.WriteString(value)
+		return
+	}
+	writeJSONString(buf, value)
+}
+
+// isJSONNumber reports whether s is a number in the JSON syntax
+func isJSONNumber(s string) bool {
+	if s == "" || (s[0] != '-' && (s[0] < '0' || s[0] > '9')) {
+		return false
+	}
+	if c := s[len(s)-1]; c < '0' || c > '9' {
+		return false
+	}
+	return json.Valid([]byte(s))
+}
+
+// writeJSONString writes s as a JSON string, without escaping the HTML
+// characters
+func writeJSONString(buf *bytes.Buffer, s string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	buf.Truncate(buf.Len() - 1) // The newline written by Encode
+}
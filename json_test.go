@@ -0,0 +1,155 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+const jsonTestData = `product=goini
+debug=false
+[server]
+port=8080
+ratio=1.5
+hosts=a.com, b.com
+version=08
+name="x<y"
+[server.http]
+gzip=true
+`
+
+func jsonString(t *testing.T, ini *INI, opts JSONOptions) string {
+	var buf bytes.Buffer
+	err := ini.WriteJSON(&buf, opts)
+	assert.Equal(t, nil, err)
+	return buf.String()
+}
+
+func TestWriteJSON(t *testing.T) {
+	ini := parseTestINI(t, jsonTestData, parseSections)
+	assert.Equal(t, jsonString(t, ini, JSONOptions{}), `{"product":"goini","debug":"false",`+
+		`"server":{"port":"8080","ratio":"1.5","hosts":"a.com, b.com","version":"08","name":"\"x<y\""},`+
+		`"server.http":{"gzip":"true"}}`)
+
+	assert.Equal(t, jsonString(t, ini, JSONOptions{DefaultSection: "global", InferTypes: true}),
+		`{"global":{"product":"goini","debug":false},`+
+			`"server":{"port":8080,"ratio":1.5,"hosts":["a.com","b.com"],"version":"08","name":"\"x<y\""},`+
+			`"server.http":{"gzip":true}}`)
+
+	assert.Equal(t, jsonString(t, New(), JSONOptions{Indent: "  "}), "{}\n")
+	ini = New()
+	ini.SectionSet("s", "a", "1")
+	assert.Equal(t, jsonString(t, ini, JSONOptions{Indent: "  "}), "{\n  \"s\": {\n    \"a\": \"1\"\n  }\n}\n")
+
+	// MarshalJSON is used by json.Marshal, which escapes the HTML characters
+	ini.SectionSet("s", "b", "<")
+	b, err := json.Marshal(map[string]*INI{"config": ini})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, string(b), `{"config":{"s":{"a":"1","b":"\u003c"}}}`)
+}
+
+func TestWriteJSONCollision(t *testing.T) {
+	ini := New()
+	ini.Set("server", "x")
+	ini.SectionSet("server", "port", "80")
+	var buf bytes.Buffer
+	assert.NotEqual(t, nil, ini.WriteJSON(&buf, JSONOptions{}))
+	assert.Equal(t, nil, ini.WriteJSON(&buf, JSONOptions{DefaultSection: "global"}))
+	assert.NotEqual(t, nil, ini.WriteJSON(&buf, JSONOptions{DefaultSection: "server"}))
+}
+
+func TestParseJSON(t *testing.T) {
+	ini := New()
+	err := json.Unmarshal([]byte(`{
+		"product": "goini",
+		"count": 3,
+		"server": {
+			"port": 8080,
+			"debug": false,
+			"hosts": ["a.com", "b.com", 1],
+			"none": null,
+			"http": {"gzip": true},
+			"mode": "release"
+		}
+	}`), ini)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, writeString(t, ini), "product=goini\ncount=3\n[server]\nport=8080\ndebug=false\nhosts=a.com,b.com,1\nnone=\nmode=release\n[server.http]\ngzip=true\n")
+
+	var config struct {
+		Server struct {
+			Hosts []string `ini:"hosts"`
+			HTTP  struct {
+				Gzip bool `ini:"gzip"`
+			} `ini:"http"`
+		} `ini:"server"`
+	}
+	err = ini.Unmarshal(&config)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, config.Server.Hosts, []string{"a.com", "b.com", "1"})
+	assert.Equal(t, config.Server.HTTP.Gzip, true)
+}
+
+func TestParseJSONDefaultSection(t *testing.T) {
+	ini := New()
+	err := ini.ParseJSON([]byte(`{"global":{"a":"1"},"s":{"b":"2"}}`), JSONOptions{DefaultSection: "global"})
+	assert.Equal(t, nil, err)
+	v, _ := ini.Get("a")
+	assert.Equal(t, v, "1")
+	v, _ = ini.SectionGet("s", "b")
+	assert.Equal(t, v, "2")
+	assert.Equal(t, ini.HasSection("global"), false)
+
+	// The top level keys and the keys of the default section are merged
+	err = ini.ParseJSON([]byte(`{"a":"1","global":{"b":"2"},"s":{"c":"3"},"s":{"d":"4"}}`), JSONOptions{DefaultSection: "global"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, ini.HasKey("", "a"), true)
+	assert.Equal(t, ini.HasKey("", "b"), true)
+	assert.Equal(t, ini.HasKey("s", "c"), true)
+	assert.Equal(t, ini.HasKey("s", "d"), true)
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	ini := parseTestINI(t, jsonTestData, parseSections)
+	for _, opts := range []JSONOptions{{}, {DefaultSection: "global", Indent: "\t"}} {
+		var buf bytes.Buffer
+		err := ini.WriteJSON(&buf, opts)
+		assert.Equal(t, nil, err)
+		c := New()
+		err = c.ParseJSON(buf.Bytes(), opts)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, c.Equal(ini), true)
+	}
+
+	// InferTypes loses the whitespace around the items of the arrays
+	var buf bytes.Buffer
+	err := ini.WriteJSON(&buf, JSONOptions{InferTypes: true})
+	assert.Equal(t, nil, err)
+	c := New()
+	err = c.ParseJSON(buf.Bytes(), JSONOptions{})
+	assert.Equal(t, nil, err)
+	v, _ := c.SectionGet("server", "hosts")
+	assert.Equal(t, v, "a.com,b.com")
+	v, _ = c.SectionGet("server", "ratio")
+	assert.Equal(t, v, "1.5")
+}
+
+func TestParseJSONErrors(t *testing.T) {
+	for _, data := range []string{
+		`[]`,
+		`"a"`,
+		`{"a":`,
+		`{"a":[{}]}`,
+		`{"a":[[1]]}`,
+		`{"a":1} {}`,
+		``,
+	} {
+		err := New().ParseJSON([]byte(data), JSONOptions{})
+		assert.NotEqual(t, nil, err, data)
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+// loader stores the data loaded from another format. A section is created
+// empty when it is first used, and reused when it is used again.
+type loader struct {
+	ini      *INI
+	sections map[string]Kvmap // The sections created, by stored name
+}
+
+func newLoader(ini *INI) *loader {
+	l := &loader{ini: ini, sections: make(map[string]Kvmap)}
+	l.section(DefaultSection, 0)
+	return l
+}
+
+// section returns the stored name and the keys of the section spelled
+// spelling, of which the header is at line
+func (l *loader) section(spelling string, line int) (string, Kvmap) {
+	section := l.ini.sectionName(spelling)
+	kv, ok := l.sections[section]
+	if !ok {
+		section, kv = l.ini.storeSection(spelling)
+		l.sections[section] = kv
+	}
+	if line > 0 {
+		l.ini.sectionLines[section] = line
+	}
+	return section, kv
+}
+
+// set stores the key/value pair of the section spelled section, which is
+// at line
+func (l *loader) set(section, key, value string, line int) {
+	section, kv := l.section(section, 0)
+	l.ini.storeKey(section, kv, key, value)
+	l.ini.keyLines[lineKey{section, l.ini.keyName(key)}] = line
+}
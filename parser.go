@@ -0,0 +1,307 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"unicode"
+)
+
+// DefaultMaxLineSize is the maximum size of a line read by a Parser, unless
+// SetMaxLineSize changes it
+const DefaultMaxLineSize = 1 << 20
+
+// EventType is the type of an Event
+type EventType int
+
+const (
+	// SectionEvent is a section header
+	SectionEvent EventType = iota + 1
+
+	// KeyValueEvent is a key/value pair
+	KeyValueEvent
+
+	// CommentEvent is a comment line
+	CommentEvent
+)
+
+// Event is a section header, a key/value pair or a comment line returned
+// by Parser.Next
+type Event struct {
+	Type    EventType
+	Section string // The name of the section, or of the section of the key
+	Key     string // The key of a KeyValueEvent
+	Value   string // The value of a KeyValueEvent
+	Comment string // The comment line, or the inline comment of the value
+	Line    int    // The line number, counted from 1
+}
+
+// Parser reads INI data from an io.Reader incrementally. Only the line
+// which is being parsed is buffered, so the memory used does not depend
+// on the size of the data, but on the maximum line size.
+type Parser struct {
+	d        *Dialect
+	scanner  *bufio.Scanner
+	splitter *splitter // The splitter of scanner
+	data     []byte    // The data which are not parsed yet if scanner is nil
+	sep      []byte    // The line separator if scanner is nil
+	line     int
+	section  string            // The name of the current section
+	vars     map[string]string // The values of the current section if the dialect expands them
+	err      error
+}
+
+// token is a parsed line. The byte slices are only valid until the next
+// call to scan.
+type token struct {
+	typ     EventType
+	name    []byte // The section name, the key or the comment line
+	value   []byte
+	comment []byte // The inline comment
+	line    int
+}
+
+// NewParser returns a Parser reading the data from r in dialect d. The
+// lines are limited to DefaultMaxLineSize bytes.
+func NewParser(r io.Reader, d Dialect) *Parser {
+	p := newParser(r, newDialect(d))
+	p.SetMaxLineSize(DefaultMaxLineSize)
+	return p
+}
+
+func newParser(r io.Reader, d *Dialect) *Parser {
+	scanner, s := newScanner(r, d.LineSeparator)
+	return &Parser{d: d, scanner: scanner, splitter: s}
+}
+
+// SetMaxLineSize sets the maximum size of a line, which makes Next return
+// an error when exceeded. A size of 0 does not limit the lines.
+func (p *Parser) SetMaxLineSize(size int) {
+	p.splitter.max = size
+}
+
+// newScanner returns a bufio.Scanner reading the data from r, which are
+// separated by sep, and its splitter. The size of the data between two
+// separators is not limited, unless the max of the splitter is set.
+func newScanner(r io.Reader, sep string) (*bufio.Scanner, *splitter) {
+	s := &splitter{sep: []byte(sep)}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, math.MaxInt)
+	scanner.Split(s.split)
+	return scanner, s
+}
+
+// splitter splits the data read by a bufio.Scanner at a separator
+type splitter struct {
+	sep      []byte
+	max      int // The maximum size of the data between two separators, or 0
+	searched int // The size of the data which is already searched for sep
+}
+
+func (s *splitter) split(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	// The data which does not contain the separator is kept until the next
+	// call, which only searches the data read since then. The separator
+	// may be split across the reads, so its start is searched again.
+	end := -1
+	if i := bytes.Index(data[s.searched:], s.sep); i >= 0 {
+		end = s.searched + i
+		advance = end + len(s.sep)
+	} else if atEOF && len(data) > 0 {
+		end, advance = len(data), len(data)
+	}
+	if s.max > 0 && (end > s.max || (end < 0 && len(data) > s.max)) {
+		return 0, nil, errors.New("The line is longer than " + strconv.Itoa(s.max) + " bytes")
+	}
+	if end < 0 {
+		s.searched = max(0, len(data)-len(s.sep)+1)
+		return 0, nil, nil
+	}
+	s.searched = 0
+	return advance, data[:end], nil
+}
+
+// Next returns the next event. It returns io.EOF at the end of the data,
+// or the first error encountered while reading or parsing the data.
+// Blank lines are skipped.
+func (p *Parser) Next() (Event, error) {
+	t, err := p.scan()
+	if err != nil {
+		return Event{}, err
+	}
+
+	e := Event{Type: t.typ, Section: p.section, Line: t.line}
+	switch t.typ {
+	case SectionEvent:
+		p.section = string(t.name)
+		e.Section = p.section
+	case KeyValueEvent:
+		e.Key = string(t.name)
+		e.Value = string(t.value)
+		e.Comment = string(t.comment)
+	case CommentEvent:
+		e.Comment = string(t.name)
+	}
+	return e, nil
+}
+
+// scan parses the next line which is not blank
+func (p *Parser) scan() (token, error) {
+	if p.err != nil {
+		return token{}, p.err
+	}
+	for {
+		line, ok := p.readLine()
+		if !ok {
+			break
+		}
+		p.line++
+		joined := 0
+		if p.d.LineContinuation {
+			line, joined = p.continueLine(line)
+		}
+		t, ok, err := p.parseLine(line)
+		p.line += joined
+		if err != nil {
+			p.err = err
+			return token{}, err
+		}
+		if ok {
+			if p.d.Expand {
+				p.define(t)
+			}
+			return t, nil
+		}
+	}
+
+	if p.scanner != nil {
+		p.err = p.scanner.Err()
+	}
+	if p.err == nil {
+		p.err = io.EOF
+	}
+	return token{}, p.err
+}
+
+// readLine returns the next line, or false at the end of the data
+func (p *Parser) readLine() ([]byte, bool) {
+	if p.scanner != nil {
+		if !p.scanner.Scan() {
+			return nil, false
+		}
+		return p.scanner.Bytes(), true
+	}
+
+	if p.data == nil {
+		return nil, false
+	}
+	line := p.data
+	if i := bytes.Index(p.data, p.sep); i >= 0 {
+		line, p.data = p.data[:i], p.data[i+len(p.sep):]
+	} else {
+		p.data = nil
+	}
+	return line, true
+}
+
+// continueLine joins the line and the lines which continue it, and returns
+// the number of lines joined to it
+func (p *Parser) continueLine(line []byte) ([]byte, int) {
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	if !endsWithEscape(line) || p.d.isComment(bytes.TrimSpace(line)) {
+		return line, 0
+	}
+
+	// The joined line is a new slice, which is not overwritten by the next
+	// lines as the memory of the scanner is
+	joined := append([]byte(nil), line[:len(line)-1]...)
+	n := 0
+	for {
+		next, ok := p.readLine()
+		if !ok {
+			break
+		}
+		n++
+		next = bytes.TrimLeft(bytes.TrimSuffix(next, []byte("\r")), " \t\f")
+		if !endsWithEscape(next) {
+			joined = append(joined, next...)
+			break
+		}
+		joined = append(joined, next[:len(next)-1]...)
+	}
+	return joined, n
+}
+
+// endsWithEscape reports whether line ends with an odd number of backslashes
+func endsWithEscape(line []byte) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// parseLine parses one line. ok is false if the line is blank.
+func (p *Parser) parseLine(raw []byte) (t token, ok bool, err error) {
+	d := p.d
+	t.line = p.line
+	line := bytes.TrimSpace(raw)
+	if len(line) == 0 {
+		return t, false, nil
+	}
+	if d.isComment(line) {
+		t.typ, t.name = CommentEvent, line
+		return t, true, nil
+	}
+	if name, ok := d.sectionHeader(line); ok {
+		t.typ, t.name = SectionEvent, bytes.TrimSpace(name)
+		return t, true, nil
+	}
+
+	if d.KeepValueSpace || d.Escapes {
+		// Only the leading whitespace of the key is trimmed. As in Java, the
+		// trailing whitespace of the values is kept with the escapes.
+		line = bytes.TrimLeftFunc(raw, unicode.IsSpace)
+	}
+	k, v, ok := d.splitKeyValue(line)
+	if !ok && d.AllowNoValue {
+		k, v, ok = line, nil, true
+	}
+	if !ok {
+		// ERROR happened when passing
+		return t, false, errors.New("Came accross an error : " + string(line) + " is NOT a valid key/value pair")
+	}
+
+	if d.Escapes {
+		if !d.KeepValueSpace {
+			v = bytes.TrimLeftFunc(v, unicode.IsSpace)
+		}
+		t.typ, t.name, t.value = KeyValueEvent, unescapeProperties(trimEscaped(k)), unescapeProperties(v)
+		return t, true, nil
+	}
+
+	t.typ, t.name = KeyValueEvent, bytes.TrimSpace(k)
+	if d.ExportPrefix {
+		t.name = trimExport(t.name)
+	}
+	if !d.KeepValueSpace {
+		v = bytes.TrimSpace(v)
+	}
+	if d.InlineComments {
+		v, t.comment = d.splitComment(v)
+	}
+	if d.Expand {
+		v = p.expand(v)
+	}
+	if d.Quotes != "" {
+		v = d.unquote(v)
+	}
+	t.value = v
+	return t, true, nil
+}
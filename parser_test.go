@@ -0,0 +1,137 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestParserNext(t *testing.T) {
+	raw := "; header\nproduct = goini\n\n[server]\n# the port\nport = 8080 ; default\n"
+	d := DefaultDialect
+	d.InlineComments = true
+	p := NewParser(strings.NewReader(raw), d)
+
+	expected := []Event{
+		{Type: CommentEvent, Comment: "; header", Line: 1},
+		{Type: KeyValueEvent, Key: "product", Value: "goini", Line: 2},
+		{Type: SectionEvent, Section: "server", Line: 4},
+		{Type: CommentEvent, Section: "server", Comment: "# the port", Line: 5},
+		{Type: KeyValueEvent, Section: "server", Key: "port", Value: "8080", Comment: "; default", Line: 6},
+	}
+	for _, e := range expected {
+		got, err := p.Next()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, got, e)
+	}
+	_, err := p.Next()
+	assert.Equal(t, err, io.EOF)
+	_, err = p.Next()
+	assert.Equal(t, err, io.EOF)
+}
+
+func TestParserError(t *testing.T) {
+	p := NewParser(strings.NewReader("a=1\nnot a pair\nb=2\n"), DefaultDialect)
+	_, err := p.Next()
+	assert.Equal(t, nil, err)
+	_, err = p.Next()
+	assert.NotEqual(t, nil, err)
+	_, err2 := p.Next()
+	assert.Equal(t, err2, err)
+
+	readErr := errors.New("read error")
+	p = NewParser(io.MultiReader(strings.NewReader("a=1\n"), iotest.ErrReader(readErr)), DefaultDialect)
+	_, err = p.Next()
+	assert.Equal(t, nil, err)
+	_, err = p.Next()
+	assert.Equal(t, err, readErr)
+}
+
+func TestParserMultiByteSeparator(t *testing.T) {
+	raw := "a:1||b:2|x||c:3|||d:4||"
+	d := Dialect{LineSeparator: "||", KeyValueSeparators: []string{":"}}
+
+	// One byte per read splits every separator
+	p := NewParser(iotest.OneByteReader(strings.NewReader(raw)), d)
+	var got []string
+	for {
+		e, err := p.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.Equal(t, nil, err)
+		got = append(got, e.Key+"="+e.Value)
+	}
+	assert.Equal(t, got, []string{"a=1", "b=2|x", "c=3", "|d=4"})
+}
+
+func TestParserMaxLineSize(t *testing.T) {
+	raw := "a=12345678\nb=123456789\nc=3"
+	p := NewParser(iotest.OneByteReader(strings.NewReader(raw)), DefaultDialect)
+	p.SetMaxLineSize(10)
+	e, err := p.Next()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, e.Value, "12345678")
+	_, err = p.Next()
+	assert.Equal(t, err.Error(), "The line is longer than 10 bytes")
+	_, err = p.Next()
+	assert.NotEqual(t, nil, err)
+
+	p = NewParser(strings.NewReader(raw), DefaultDialect)
+	p.SetMaxLineSize(0)
+	n := 0
+	for {
+		if _, err = p.Next(); err != nil {
+			break
+		}
+		n++
+	}
+	assert.Equal(t, err, io.EOF)
+	assert.Equal(t, n, 3)
+}
+
+func TestParserBlankValue(t *testing.T) {
+	p := NewParser(strings.NewReader("a=\n=b"), DefaultDialect)
+	e, err := p.Next()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, e.Key, "a")
+	assert.Equal(t, e.Value, "")
+	e, err = p.Next()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, e.Key, "")
+	assert.Equal(t, e.Value, "b")
+}
+
+func TestParseFromStreams(t *testing.T) {
+	var buf bytes.Buffer
+	for i := 0; i < 1000; i++ {
+		buf.WriteString("[s")
+		buf.WriteString(string(rune('a' + i%26)))
+		buf.WriteString("]\r\nkey = value\r\n")
+	}
+	buf.WriteString("long = " + strings.Repeat("x", 100000))
+
+	ini := New()
+	ini.SetParseSection(true)
+	err := ini.ParseFrom(iotest.HalfReader(bytes.NewReader(buf.Bytes())), "\r\n", "=")
+	assert.Equal(t, nil, err)
+
+	expected := New()
+	expected.SetParseSection(true)
+	err = expected.Parse(buf.Bytes(), "\r\n", "=")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, ini.Equal(expected), true)
+
+	v, _ := ini.SectionGet("sl", "long")
+	assert.Equal(t, len(v), 100000)
+	assert.Equal(t, ini.Line("sl", "long"), 2001)
+}
@@ -0,0 +1,238 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"sort"
+	"strconv"
+)
+
+// PatchOp is the kind of an Operation
+type PatchOp string
+
+const (
+	PatchAdd    PatchOp = "add"    // Adds a key which must not exist
+	PatchSet    PatchOp = "set"    // Sets the value of a key
+	PatchDelete PatchOp = "delete" // Deletes a key which must exist
+	PatchRename PatchOp = "rename" // Renames a key, or a section if Key is empty
+)
+
+// Operation is one step of a Patch.
+//
+// Old guards against overwriting local edits: if it is not nil the key
+// must currently have exactly that value, otherwise the Patch can not be
+// applied. It is also needed to invert set and delete operations.
+type Operation struct {
+	Op      PatchOp `json:"op"`
+	Section string  `json:"section"`
+	Key     string  `json:"key,omitempty"`
+	Value   string  `json:"value,omitempty"` // The new value of add and set
+	To      string  `json:"to,omitempty"`    // The new name of rename
+	Old     *string `json:"old,omitempty"`
+}
+
+// Patch is a list of operations which are applied in order.
+// It can be serialized as JSON by encoding/json or as INI by MarshalINI.
+type Patch []Operation
+
+// NewPatch returns the Patch which turns a into b. Every operation
+// carries the old value, so the Patch can be inverted.
+func NewPatch(a, b *INI) Patch {
+	var p Patch
+	for _, kd := range Diff(a, b).Keys() {
+		switch kd.Kind {
+		case DiffAdded:
+			p = append(p, Operation{Op: PatchAdd, Section: kd.Section, Key: kd.Key, Value: kd.New})
+		case DiffRemoved:
+			p = append(p, Operation{Op: PatchDelete, Section: kd.Section, Key: kd.Key, Old: stringPtr(kd.Old)})
+		case DiffChanged:
+			p = append(p, Operation{Op: PatchSet, Section: kd.Section, Key: kd.Key, Value: kd.New, Old: stringPtr(kd.Old)})
+		}
+	}
+	return p
+}
+
+// Apply applies all the operations of p to this INI, or none of them if
+// any operation fails. On success it returns the Patch which rolls back
+// the changes. The subscribers are notified of the changes.
+func (ini *INI) Apply(p Patch) (rollback Patch, err error) {
+	work := ini.copyData()
+
+	for i, op := range p {
+		undo, err := applyOperation(work, op)
+		if err != nil {
+			return nil, errors.New("Apply operation #" + strconv.Itoa(i) + " failed : " + err.Error())
+		}
+		rollback = append(rollback, undo)
+	}
+
+	// The rollback operations must be applied in the reverse order
+	for i, j := 0, len(rollback)-1; i < j; i, j = i+1, j-1 {
+		rollback[i], rollback[j] = rollback[j], rollback[i]
+	}
+
+	changes := diffSectionMap(ini.sections, work.sections)
+	ini.swapData(work)
+	ini.notify(changes)
+	return rollback, nil
+}
+
+// Invert returns the Patch which undoes p. Every set and delete
+// operation of p must carry its Old value.
+func (p Patch) Invert() (Patch, error) {
+	inverse := make(Patch, 0, len(p))
+	for i := len(p) - 1; i >= 0; i-- {
+		op := p[i]
+		switch op.Op {
+		case PatchAdd:
+			inverse = append(inverse, Operation{Op: PatchDelete, Section: op.Section, Key: op.Key, Old: stringPtr(op.Value)})
+		case PatchSet:
+			if op.Old == nil {
+				return nil, errors.New("Can not invert operation #" + strconv.Itoa(i) + " : the old value is unknown")
+			}
+			inverse = append(inverse, Operation{Op: PatchSet, Section: op.Section, Key: op.Key, Value: *op.Old, Old: stringPtr(op.Value)})
+		case PatchDelete:
+			if op.Old == nil {
+				return nil, errors.New("Can not invert operation #" + strconv.Itoa(i) + " : the old value is unknown")
+			}
+			inverse = append(inverse, Operation{Op: PatchAdd, Section: op.Section, Key: op.Key, Value: *op.Old})
+		case PatchRename:
+			if op.Key == "" {
+				inverse = append(inverse, Operation{Op: PatchRename, Section: op.To, To: op.Section})
+			} else {
+				inverse = append(inverse, Operation{Op: PatchRename, Section: op.Section, Key: op.To, To: op.Key})
+			}
+		default:
+			return nil, errors.New("Unknown patch operation : " + string(op.Op))
+		}
+	}
+	return inverse, nil
+}
+
+// MarshalINI serializes p as INI, one section per operation:
+//
+//	[1]
+//	op=set
+//	section=sss
+//	key=a
+//	value=aval
+//	old=bval
+//
+// The values are written as they are, so values with leading or trailing
+// spaces or line breaks can only be serialized as JSON.
+func (p Patch) MarshalINI() []byte {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	for i, op := range p {
+		w.WriteString("[" + strconv.Itoa(i+1) + "]\n")
+		w.WriteString("op=" + string(op.Op) + "\n")
+		w.WriteString("section=" + op.Section + "\n")
+		if op.Key != "" {
+			w.WriteString("key=" + op.Key + "\n")
+		}
+		if op.Op == PatchAdd || op.Op == PatchSet {
+			w.WriteString("value=" + op.Value + "\n")
+		}
+		if op.To != "" {
+			w.WriteString("to=" + op.To + "\n")
+		}
+		if op.Old != nil {
+			w.WriteString("old=" + *op.Old + "\n")
+		}
+	}
+	w.Flush()
+	return buf.Bytes()
+}
+
+// ParsePatchINI parses a Patch serialized by MarshalINI
+func ParsePatchINI(data []byte) (Patch, error) {
+	ini := New()
+	ini.SetParseSection(true)
+	ini.SetSkipCommits(true)
+	if err := ini.Parse(data, DefaultLineSeparator, DefaultKeyValueSeparator); err != nil {
+		return nil, err
+	}
+
+	var indexes []int
+	for section := range ini.sections {
+		if section == DefaultSection {
+			continue
+		}
+		i, err := strconv.Atoi(section)
+		if err != nil {
+			return nil, errors.New("Invalid patch operation section [" + section + "]")
+		}
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	p := make(Patch, 0, len(indexes))
+	for _, i := range indexes {
+		kv := ini.sections[strconv.Itoa(i)]
+		op := Operation{
+			Op:      PatchOp(kv["op"]),
+			Section: kv["section"],
+			Key:     kv["key"],
+			Value:   kv["value"],
+			To:      kv["to"],
+		}
+		if old, ok := kv["old"]; ok {
+			op.Old = stringPtr(old)
+		}
+		p = append(p, op)
+	}
+	return p, nil
+}
+
+// applyOperation applies op to ini and returns the operation which
+// undoes it.
+func applyOperation(ini *INI, op Operation) (Operation, error) {
+	old, found := ini.SectionGet(op.Section, op.Key)
+	if op.Old != nil && (!found || old != *op.Old) {
+		return op, errors.New("[" + op.Section + "] " + op.Key + " has been modified")
+	}
+
+	switch op.Op {
+	case PatchAdd:
+		if found {
+			return op, errors.New("[" + op.Section + "] " + op.Key + " already exists")
+		}
+		fallthrough
+	case PatchSet:
+		ini.SectionSet(op.Section, op.Key, op.Value)
+		if !found {
+			return Operation{Op: PatchDelete, Section: op.Section, Key: op.Key, Old: stringPtr(op.Value)}, nil
+		}
+		return Operation{Op: PatchSet, Section: op.Section, Key: op.Key, Value: old, Old: stringPtr(op.Value)}, nil
+	case PatchDelete:
+		if !found {
+			return op, errors.New("[" + op.Section + "] " + op.Key + " does not exist")
+		}
+		ini.Delete(op.Section, op.Key)
+		return Operation{Op: PatchAdd, Section: op.Section, Key: op.Key, Value: old}, nil
+	case PatchRename:
+		if op.To == "" {
+			return op, errors.New("The new name of a rename operation is empty")
+		}
+		if op.Key == "" {
+			if err := ini.RenameSection(op.Section, op.To); err != nil {
+				return op, err
+			}
+			return Operation{Op: PatchRename, Section: op.To, To: op.Section}, nil
+		}
+		if err := ini.RenameKey(op.Section, op.Key, op.To); err != nil {
+			return op, err
+		}
+		return Operation{Op: PatchRename, Section: op.Section, Key: op.To, To: op.Key}, nil
+	}
+	return op, errors.New("Unknown patch operation : " + string(op.Op))
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
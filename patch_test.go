@@ -0,0 +1,116 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestPatchApply(t *testing.T) {
+	a := New()
+	err := a.ParseFile(filepath.Join(getTestDataDir(t), "common.ini"))
+	assert.Equal(t, nil, err)
+	b, err := LoadInheritedINI(filepath.Join(getTestDataDir(t), "project.ini"))
+	assert.Equal(t, nil, err)
+
+	p := NewPatch(a, b)
+	rollback, err := a.Apply(p)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, Diff(a, b).Equal(), true)
+
+	inverse, err := p.Invert()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, inverse, rollback)
+
+	_, err = a.Apply(rollback)
+	assert.Equal(t, nil, err)
+	v, ok := a.Get("product")
+	assert.Equal(t, v, "common")
+	assert.Equal(t, ok, true)
+	_, ok = a.SectionGet("sss", "c")
+	assert.Equal(t, ok, false)
+}
+
+func TestPatchAllOrNothing(t *testing.T) {
+	ini := New()
+	ini.SectionSet("sss", "a", "local-edit")
+
+	called := false
+	ini.SubscribeSection("sss", func(key, old, new string, deleted bool) {
+		called = true
+	})
+
+	p := Patch{
+		{Op: PatchSet, Section: "sss", Key: "b", Value: "bval"},
+		{Op: PatchSet, Section: "sss", Key: "a", Value: "aval", Old: stringPtr("aaval")},
+	}
+	_, err := ini.Apply(p)
+	assert.NotEqual(t, nil, err)
+	_, ok := ini.SectionGet("sss", "b")
+	assert.Equal(t, ok, false)
+	assert.Equal(t, called, false)
+
+	_, err = ini.Apply(Patch{{Op: PatchAdd, Section: "sss", Key: "a", Value: "x"}})
+	assert.NotEqual(t, nil, err)
+	_, err = ini.Apply(Patch{{Op: PatchDelete, Section: "sss", Key: "x"}})
+	assert.NotEqual(t, nil, err)
+	_, err = ini.Apply(Patch{{Op: "move", Section: "sss", Key: "a"}})
+	assert.NotEqual(t, nil, err)
+	_, err = Patch{{Op: PatchSet, Section: "sss", Key: "a", Value: "x"}}.Invert()
+	assert.NotEqual(t, nil, err)
+}
+
+func TestPatchRename(t *testing.T) {
+	ini := New()
+	ini.SectionSet("sss", "a", "aval")
+
+	p := Patch{
+		{Op: PatchRename, Section: "sss", Key: "a", To: "b"},
+		{Op: PatchRename, Section: "sss", To: "ddd"},
+	}
+	rollback, err := ini.Apply(p)
+	assert.Equal(t, nil, err)
+	v, ok := ini.SectionGet("ddd", "b")
+	assert.Equal(t, v, "aval")
+	assert.Equal(t, ok, true)
+	_, ok = ini.GetKvmap("sss")
+	assert.Equal(t, ok, false)
+
+	inverse, err := p.Invert()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, inverse, rollback)
+
+	_, err = ini.Apply(rollback)
+	assert.Equal(t, nil, err)
+	v, ok = ini.SectionGet("sss", "a")
+	assert.Equal(t, v, "aval")
+	assert.Equal(t, ok, true)
+}
+
+func TestPatchSerialize(t *testing.T) {
+	p := Patch{
+		{Op: PatchSet, Section: "sss", Key: "a", Value: "aval", Old: stringPtr("")},
+		{Op: PatchDelete, Section: "", Key: "b"},
+		{Op: PatchRename, Section: "sss", To: "ddd"},
+	}
+
+	q, err := ParsePatchINI(p.MarshalINI())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, q, p)
+
+	data, err := json.Marshal(p)
+	assert.Equal(t, nil, err)
+	q = nil
+	err = json.Unmarshal(data, &q)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, q, p)
+
+	_, err = ParsePatchINI([]byte("[x]\nop=set\n"))
+	assert.NotEqual(t, nil, err)
+}
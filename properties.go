@@ -0,0 +1,111 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// trimEscaped trims the whitespace around b, but keeps a trailing
+// whitespace character escaped by a backslash
+func trimEscaped(b []byte) []byte {
+	b = bytes.TrimLeftFunc(b, unicode.IsSpace)
+	t := bytes.TrimRightFunc(b, unicode.IsSpace)
+	if len(t) < len(b) && endsWithEscape(t) {
+		_, size := utf8.DecodeRune(b[len(t):])
+		t = b[:len(t)+size]
+	}
+	return t
+}
+
+// unescapeProperties replaces the escape sequences of a key or a value of
+// a .properties file. See Dialect.Escapes.
+func unescapeProperties(v []byte) []byte {
+	if !utf8.Valid(v) {
+		// ISO-8859-1 maps every byte to the rune of the same value
+		out := make([]byte, 0, 2*len(v))
+		for _, c := range v {
+			out = utf8.AppendRune(out, rune(c))
+		}
+		v = out
+	}
+	if bytes.IndexByte(v, '\\') < 0 {
+		return v
+	}
+
+	out := make([]byte, 0, len(v))
+	for i := 0; i < len(v); i++ {
+		if v[i] != '\\' || i+1 == len(v) {
+			out = append(out, v[i])
+			continue
+		}
+		i++
+		switch c := v[i]; c {
+		case 't':
+			out = append(out, '\t')
+		case 'n':
+			out = append(out, '\n')
+		case 'r':
+			out = append(out, '\r')
+		case 'f':
+			out = append(out, '\f')
+		case 'u':
+			r, n := decodeRune(v[i+1:])
+			if n == 0 {
+				// A malformed escape sequence is kept as is
+				out = append(out, '\\', c)
+				continue
+			}
+			out = utf8.AppendRune(out, r)
+			i += n
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// escapeProperties escapes a key or a value of a .properties file, so that
+// it is parsed back unchanged and only holds printable ASCII characters.
+// The separators and the comment prefixes are escaped in a key.
+func escapeProperties(s string, key bool) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == ' ' && (key || i == 0 || i == len(s)-1):
+			b.WriteString(`\ `)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\f':
+			b.WriteString(`\f`)
+		case key && (r == '=' || r == ':' || r == '#' || r == '!'):
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < ' ' || r > '~':
+			writeRuneEscape(&b, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// formatKey returns the key k as Write writes it
+func (d *Dialect) formatKey(k string) string {
+	if d.Escapes {
+		return escapeProperties(k, true)
+	}
+	return k
+}
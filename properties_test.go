@@ -0,0 +1,160 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+const propertiesTestData = `# comment \
+! comment
+key1=value1
+key2 = value2
+key3:value3
+key4 value4
+key5   :   value5
+empty
+empty2=
+multi=line one \
+      line two \
+      end
+path=c:\\dir\\file
+unicode=\u00e9t\u00e9 \ud83d\ude00
+a\=b\ c=x` + "\ntrail=x\\ \n" + `tab\tkey=\u12
+continued\\
+next=1
+`
+
+func TestParseProperties(t *testing.T) {
+	ini := parseTestINI(t, propertiesTestData, withDialect(PropertiesDialect))
+	tests := []struct {
+		key, value string
+		line       int
+	}{
+		{"key1", "value1", 3},
+		{"key2", "value2", 4},
+		{"key3", "value3", 5},
+		{"key4", "value4", 6},
+		{"key5", "value5", 7},
+		{"empty", "", 8},
+		{"empty2", "", 9},
+		{"multi", "line one line two end", 10},
+		{"path", `c:\dir\file`, 13},
+		{"unicode", "été 😀", 14},
+		{"a=b c", "x", 15},
+		{"trail", "x ", 16},
+		{"tab\tkey", `\u12`, 17},
+		{`continued\`, "", 18},
+		{"next", "1", 19},
+	}
+	for _, test := range tests {
+		v, ok := ini.Get(test.key)
+		assert.Equal(t, v, test.value, test.key)
+		assert.Equal(t, ok, true, test.key)
+		assert.Equal(t, ini.Line("", test.key), test.line, test.key)
+	}
+	assert.Equal(t, len(ini.GetAll()[DefaultSection]), len(tests))
+
+	// The same data read incrementally
+	r := New()
+	r.SetDialect(PropertiesDialect)
+	err := r.ParseFrom(strings.NewReader(propertiesTestData), "", "")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, r.Equal(ini), true)
+}
+
+func TestParsePropertiesLatin1(t *testing.T) {
+	ini := parseTestINI(t, "caf\xe9=cr\xe8me\r\nx=1\\\r\n  2\r\n", withDialect(PropertiesDialect))
+	v, _ := ini.Get("café")
+	assert.Equal(t, v, "crème")
+	v, _ = ini.Get("x")
+	assert.Equal(t, v, "12")
+}
+
+func TestParsePropertiesTrailingSpace(t *testing.T) {
+	ini := parseTestINI(t, "key1 = value1   \r\nkey2   \n  key3=\\ \t\n", withDialect(PropertiesDialect))
+	v, _ := ini.Get("key1")
+	assert.Equal(t, v, "value1   ")
+	v, ok := ini.Get("key2")
+	assert.Equal(t, v, "")
+	assert.Equal(t, ok, true)
+	v, _ = ini.Get("key3")
+	assert.Equal(t, v, " \t")
+}
+
+func TestPropertiesNotContinuedAtEOF(t *testing.T) {
+	ini := parseTestINI(t, "a=1\\", withDialect(PropertiesDialect))
+	v, _ := ini.Get("a")
+	assert.Equal(t, v, "1")
+}
+
+func TestWriteProperties(t *testing.T) {
+	ini := New()
+	ini.SetDialect(PropertiesDialect)
+	ini.Set("a=b c:d", "x")
+	ini.Set("#key", "#value")
+	ini.Set("lead", "  two spaces ")
+	ini.Set("text", "été\nline\t😀 = : \\")
+	out := writeString(t, ini)
+	assert.Equal(t, out, `a\=b\ c\:d=x
+\#key=#value
+lead=\  two spaces\ `+`
+text=\u00e9t\u00e9\nline\t\ud83d\ude00 = : \\
+`)
+	for _, c := range []byte(out) {
+		assert.Equal(t, c < 0x80, true)
+	}
+
+	c := parseTestINI(t, out, withDialect(PropertiesDialect))
+	assert.Equal(t, c.Equal(ini), true)
+}
+
+func TestWritePropertiesSection(t *testing.T) {
+	ini := New()
+	ini.SetDialect(PropertiesDialect)
+	ini.Set("a", "1")
+	ini.SectionSet("s", "b", "2")
+	var buf bytes.Buffer
+	err := ini.Write(&buf)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, buf.Len(), 0)
+
+	ini.DeleteSection("s")
+	assert.Equal(t, writeString(t, ini), "a=1\n")
+}
+
+func TestPropertiesRoundTrip(t *testing.T) {
+	ini := parseTestINI(t, propertiesTestData, withDialect(PropertiesDialect))
+	var buf bytes.Buffer
+	err := ini.Write(&buf)
+	assert.Equal(t, nil, err)
+	c := parseTestINI(t, buf.String(), withDialect(PropertiesDialect))
+	assert.Equal(t, c.Equal(ini), true)
+}
+
+func TestSplitKeyValueWhitespace(t *testing.T) {
+	d := newDialect(PropertiesDialect)
+	tests := []struct {
+		line, k, v string
+	}{
+		{"a b", "a", "b"},
+		{"a = b", "a", " b"},
+		{"a\t: b", "a", " b"},
+		{"a  = =b", "a", " =b"},
+		{`a\ b c`, `a\ b`, "c"},
+		{`a\=b=c`, `a\=b`, "c"},
+		{`a\\=b`, `a\\`, "b"},
+	}
+	for _, test := range tests {
+		k, v, ok := d.splitKeyValue([]byte(test.line))
+		assert.Equal(t, ok, true, test.line)
+		assert.Equal(t, string(k), test.k, test.line)
+		assert.Equal(t, string(v), test.v, test.line)
+	}
+}
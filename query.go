@@ -0,0 +1,315 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"errors"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Query returns the key/value pairs matching the query, in document order.
+// A query is a path, optionally followed by a filter of the sections :
+//
+//	sss.a                                the key a of the section sss
+//	a                                    the key a of the default section
+//	shard*.port                          the key port of the sections shard*
+//	server.http.*                        all the keys of the section server.http
+//	shard*.port where enabled=true       the same, only in the enabled sections
+//
+// The key is the part of the path after the last dot. The section and the
+// key are patterns with the syntax of path.Match, and a wildcard never
+// matches the default section. A path without wildcards is looked up as
+// SectionGet does. See QuerySections for the syntax of the filter.
+func (ini *INI) Query(query string) ([]Entry, error) {
+	p, where, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	section, key := DefaultSection, p
+	if pos := strings.LastIndex(p, SubsectionSeparator); pos >= 0 {
+		section, key = p[:pos], p[pos+len(SubsectionSeparator):]
+	}
+	if _, err := path.Match(key, ""); err != nil {
+		return nil, errors.New("Invalid key pattern " + strconv.Quote(key) + " : " + err.Error())
+	}
+
+	sections, err := ini.matchSections(section, where)
+	if err != nil {
+		return nil, err
+	}
+	pattern := key
+	if ini.foldKeys {
+		pattern = foldCase(key)
+	}
+	var entries []Entry
+	for _, section := range sections {
+		spelling := ini.spellSection(section)
+		if !hasMeta(key) {
+			if value, ok := ini.SectionGet(spelling, key); ok {
+				k := key
+				if _, stored := ini.sections[section][pattern]; stored {
+					k = ini.spellKey(section, pattern)
+				}
+				entries = append(entries, Entry{spelling, k, value})
+			}
+			continue
+		}
+		for _, k := range ini.keyList(section) {
+			value, ok := ini.sections[section][k]
+			if !ok {
+				continue
+			}
+			if matched, _ := path.Match(pattern, k); matched {
+				entries = append(entries, Entry{spelling, ini.spellKey(section, k), value})
+			}
+		}
+	}
+	return entries, nil
+}
+
+// QuerySections returns the names of the sections matching the query, in
+// document order. A query is a pattern of the section names with the
+// syntax of path.Match, optionally followed by a filter :
+//
+//	shard* where enabled=true
+//	* where port>=8000 && port<9000 || !port
+//
+// A filter is made of the conditions on the keys of a section :
+//
+//	key          the key exists
+//	!key         the key does not exist
+//	key=value    the value is equal to value
+//	key!=value   the value is not equal to value
+//	key<value    the value is less than value, and so are <=, > and >=
+//
+// The values are compared as numbers if both are numbers, as booleans for
+// = and != if both are booleans (see GetBool), and as strings otherwise.
+// A value may be quoted as a Go string. The conditions are combined by &&
+// and ||, where && binds tighter. A wildcard never matches the default
+// section, which is named by the empty pattern.
+func (ini *INI) QuerySections(query string) ([]string, error) {
+	pattern, where, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	sections, err := ini.matchSections(pattern, where)
+	if err != nil {
+		return nil, err
+	}
+	for i, section := range sections {
+		sections[i] = ini.spellSection(section)
+	}
+	return sections, nil
+}
+
+// parseQuery splits the query into the path and the filter
+func parseQuery(query string) (p string, where filter, err error) {
+	p = strings.TrimSpace(query)
+	if pos := strings.Index(p, " where "); pos >= 0 {
+		where, err = parseFilter(p[pos+len(" where "):])
+		if err != nil {
+			return "", nil, err
+		}
+		p = strings.TrimSpace(p[:pos])
+	}
+	return p, where, nil
+}
+
+// matchSections returns the stored names of the sections matching the
+// pattern and the filter
+func (ini *INI) matchSections(pattern string, where filter) ([]string, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, errors.New("Invalid section pattern " + strconv.Quote(pattern) + " : " + err.Error())
+	}
+
+	var sections []string
+	if !hasMeta(pattern) {
+		section := ini.sectionName(pattern)
+		if _, ok := ini.sections[section]; ok && where.match(ini, section) {
+			sections = append(sections, section)
+		}
+		return sections, nil
+	}
+
+	if ini.foldSections {
+		pattern = foldCase(pattern)
+	}
+	for _, section := range ini.sectionList() {
+		if section == DefaultSection {
+			continue
+		}
+		if _, ok := ini.sections[section]; !ok {
+			continue
+		}
+		if matched, _ := path.Match(pattern, section); matched && where.match(ini, section) {
+			sections = append(sections, section)
+		}
+	}
+	return sections, nil
+}
+
+// hasMeta reports whether the pattern holds any wildcard of path.Match
+func hasMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, `*?[\`)
+}
+
+// filter is a disjunction of conjunctions of conditions.
+// The nil filter matches every section.
+type filter [][]condition
+
+type condition struct {
+	key   string
+	op    string // "" if the key must exist, "!" if it must not
+	value string
+}
+
+// The comparison operators, the longest ones first
+var filterOps = []string{"!=", "<=", ">=", "=", "<", ">"}
+
+// parseFilter parses the filter expression
+func parseFilter(expr string) (filter, error) {
+	var f filter
+	for _, or := range splitFilter(expr, "||") {
+		var and []condition
+		for _, s := range splitFilter(or, "&&") {
+			c, err := parseCondition(strings.TrimSpace(s))
+			if err != nil {
+				return nil, errors.New("Invalid filter " + strconv.Quote(expr) + " : " + err.Error())
+			}
+			and = append(and, c)
+		}
+		f = append(f, and)
+	}
+	return f, nil
+}
+
+// splitFilter splits expr at the operator sep which is not quoted
+func splitFilter(expr, sep string) []string {
+	var parts []string
+	quoted := false
+	start := 0
+	for i := 0; i < len(expr); i++ {
+		switch {
+		case expr[i] == '\\' && quoted:
+			i++
+		case expr[i] == '"':
+			quoted = !quoted
+		case !quoted && strings.HasPrefix(expr[i:], sep):
+			parts = append(parts, expr[start:i])
+			start = i + len(sep)
+			i += len(sep) - 1
+		}
+	}
+	return append(parts, expr[start:])
+}
+
+func parseCondition(s string) (condition, error) {
+	if strings.HasPrefix(s, "!") && !strings.ContainsAny(s, "=<>") {
+		key := strings.TrimSpace(s[1:])
+		if key == "" {
+			return condition{}, errors.New("missing key")
+		}
+		return condition{key: key, op: "!"}, nil
+	}
+
+	pos, op := -1, ""
+	for _, o := range filterOps {
+		if i := strings.Index(s, o); i >= 0 && (pos < 0 || i < pos) {
+			pos, op = i, o
+		}
+	}
+	if pos < 0 {
+		if s == "" {
+			return condition{}, errors.New("missing condition")
+		}
+		return condition{key: s}, nil
+	}
+
+	c := condition{key: strings.TrimSpace(s[:pos]), op: op, value: strings.TrimSpace(s[pos+len(op):])}
+	if c.key == "" {
+		return condition{}, errors.New("missing key before " + op)
+	}
+	if strings.HasPrefix(c.value, `"`) {
+		v, err := strconv.Unquote(c.value)
+		if err != nil {
+			return condition{}, errors.New("bad quoted value " + c.value)
+		}
+		c.value = v
+	}
+	return c, nil
+}
+
+// match reports whether the stored section satisfies the filter
+func (f filter) match(ini *INI, section string) bool {
+	if f == nil {
+		return true
+	}
+	spelling := ini.spellSection(section)
+	for _, and := range f {
+		ok := true
+		for _, c := range and {
+			value, found := ini.SectionGet(spelling, c.key)
+			if !c.match(value, found) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c condition) match(value string, found bool) bool {
+	switch c.op {
+	case "":
+		return found
+	case "!":
+		return !found
+	}
+	if !found {
+		return false
+	}
+
+	cmp := strings.Compare(value, c.value)
+	x, err1 := strconv.ParseFloat(value, 64)
+	y, err2 := strconv.ParseFloat(c.value, 64)
+	if err1 == nil && err2 == nil {
+		switch {
+		case x < y:
+			cmp = -1
+		case x > y:
+			cmp = 1
+		default:
+			cmp = 0
+		}
+	} else if c.op == "=" || c.op == "!=" {
+		a, ok1 := parseBool(value)
+		b, ok2 := parseBool(c.value)
+		if ok1 && ok2 {
+			cmp = 1
+			if a == b {
+				cmp = 0
+			}
+		}
+	}
+
+	switch c.op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	}
+	return cmp >= 0 // ">="
+}
@@ -0,0 +1,107 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+const queryTestData = `port=1
+[shard1]
+port=8001
+enabled=true
+[shard2]
+port=8002
+enabled=false
+[shard3]
+port=9003
+enabled=yes
+name=a && b
+[server.http]
+port=80
+timeout=5
+[server.grpc]
+port=90
+`
+
+func TestQuery(t *testing.T) {
+	ini := parseTestINI(t, queryTestData, parseSections)
+	tests := []struct {
+		query   string
+		entries []Entry
+	}{
+		{"shard2.port", []Entry{{"shard2", "port", "8002"}}},
+		{"port", []Entry{{"", "port", "1"}}},
+		{"shard2.none", nil},
+		{"none.port", nil},
+		{"shard*.port", []Entry{{"shard1", "port", "8001"}, {"shard2", "port", "8002"}, {"shard3", "port", "9003"}}},
+		{"*.port", []Entry{{"shard1", "port", "8001"}, {"shard2", "port", "8002"}, {"shard3", "port", "9003"}, {"server.http", "port", "80"}, {"server.grpc", "port", "90"}}},
+		{"server.http.*", []Entry{{"server.http", "port", "80"}, {"server.http", "timeout", "5"}}},
+		{"server.*.port", []Entry{{"server.http", "port", "80"}, {"server.grpc", "port", "90"}}},
+		{"shard[13].p?rt", []Entry{{"shard1", "port", "8001"}, {"shard3", "port", "9003"}}},
+		{"shard*.port where enabled=true", []Entry{{"shard1", "port", "8001"}, {"shard3", "port", "9003"}}},
+		{"shard1.port where enabled=false", nil},
+		{" shard*.enabled where port>8001 ", []Entry{{"shard2", "enabled", "false"}, {"shard3", "enabled", "yes"}}},
+	}
+	for _, test := range tests {
+		entries, err := ini.Query(test.query)
+		assert.Equal(t, nil, err, test.query)
+		assert.Equal(t, entries, test.entries, test.query)
+	}
+}
+
+func TestQueryCaseInsensitive(t *testing.T) {
+	ini := parseTestINI(t, foldTestData, caseInsensitive(true, true))
+	entries, err := ini.Query("SERV*.PORT")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, len(entries), 1)
+	assert.Equal(t, entries[0], Entry{"Server", "Port", "8080"})
+}
+
+func TestQuerySections(t *testing.T) {
+	ini := parseTestINI(t, queryTestData, parseSections)
+	tests := []struct {
+		query    string
+		sections []string
+	}{
+		{"*", []string{"shard1", "shard2", "shard3", "server.http", "server.grpc"}},
+		{"", []string{""}},
+		{"server.http", []string{"server.http"}},
+		{"* where enabled", []string{"shard1", "shard2", "shard3"}},
+		{"* where !enabled", []string{"server.http", "server.grpc"}},
+		{"* where enabled=true", []string{"shard1", "shard3"}},
+		{"* where enabled!=true", []string{"shard2"}},
+		{"* where port>=8002 && port<9000 || timeout", []string{"shard2", "server.http"}},
+		{"* where port<=80", []string{"server.http"}},
+		{"* where port=80.0", []string{"server.http"}},
+		{`* where name="a && b"`, []string{"shard3"}},
+		{"* where name=a", nil},
+		{"shard? where port>8002 || enabled=no", []string{"shard2", "shard3"}},
+	}
+	for _, test := range tests {
+		sections, err := ini.QuerySections(test.query)
+		assert.Equal(t, nil, err, test.query)
+		assert.Equal(t, sections, test.sections, test.query)
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	ini := parseTestINI(t, queryTestData, parseSections)
+	for _, query := range []string{
+		"shard[.port",
+		"shard.[",
+		"* where =1",
+		"* where a && ",
+		"* where !",
+		`* where a="b`,
+	} {
+		_, err := ini.Query(query)
+		assert.NotEqual(t, nil, err, query)
+	}
+	_, err := ini.QuerySections("* where port>1 ||")
+	assert.NotEqual(t, nil, err)
+}
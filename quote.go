@@ -0,0 +1,218 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// quotedLen returns the length of the quoted string at the start of v,
+// including both quotation marks, or -1 if v does not start with one of
+// quotes or the quotation mark is not closed. A backslash escapes the
+// next character in a double quoted string, while a single quoted string
+// ends at the next single quotation mark.
+func quotedLen(v []byte, quotes string) int {
+	if len(v) == 0 || strings.IndexByte(quotes, v[0]) < 0 {
+		return -1
+	}
+	q := v[0]
+	for i := 1; i < len(v); i++ {
+		switch {
+		case v[i] == '\\' && q == '"':
+			i++
+		case v[i] == q:
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// unquote removes a matching pair of quotation marks enclosing v.
+// The escape sequences \n, \t, \r, \", \', \\ and \uXXXX are replaced in
+// a double quoted value, and a single quoted value is taken literally.
+// v is returned unchanged if it is not enclosed by quotation marks.
+func (d *Dialect) unquote(v []byte) []byte {
+	if quotedLen(v, d.Quotes) != len(v) {
+		return v
+	}
+	if v[0] == '"' {
+		return unescape(v[1 : len(v)-1])
+	}
+	return v[1 : len(v)-1]
+}
+
+// unescape replaces the escape sequences in v. An unknown or malformed
+// escape sequence is kept as is.
+func unescape(v []byte) []byte {
+	if bytes.IndexByte(v, '\\') < 0 {
+		return v
+	}
+
+	out := make([]byte, 0, len(v))
+	for i := 0; i < len(v); i++ {
+		if v[i] != '\\' || i+1 == len(v) {
+			out = append(out, v[i])
+			continue
+		}
+		switch c := v[i+1]; c {
+		case 'n':
+			out = append(out, '\n')
+		case 't':
+			out = append(out, '\t')
+		case 'r':
+			out = append(out, '\r')
+		case '"', '\'', '\\':
+			out = append(out, c)
+		case 'u':
+			r, n := decodeRune(v[i+2:])
+			if n == 0 {
+				out = append(out, v[i])
+				continue
+			}
+			out = utf8.AppendRune(out, r)
+			i += n
+		default:
+			out = append(out, v[i])
+			continue
+		}
+		i++
+	}
+	return out
+}
+
+// decodeRune decodes the XXXX of a \uXXXX escape sequence at the start of
+// v, which may be followed by \uXXXX encoding the low surrogate of a UTF-16
+// surrogate pair. It returns the rune and the number of bytes decoded, or
+// 0 if v does not start with 4 hexadecimal digits.
+func decodeRune(v []byte) (rune, int) {
+	hex4 := func(v []byte) (rune, bool) {
+		if len(v) < 4 {
+			return 0, false
+		}
+		n, err := strconv.ParseUint(string(v[:4]), 16, 16)
+		return rune(n), err == nil
+	}
+
+	r, ok := hex4(v)
+	if !ok {
+		return 0, 0
+	}
+	if utf16.IsSurrogate(r) && len(v) >= 10 && v[4] == '\\' && v[5] == 'u' {
+		if r2, ok := hex4(v[6:]); ok {
+			if pair := utf16.DecodeRune(r, r2); pair != utf8.RuneError {
+				return pair, 10
+			}
+		}
+	}
+	return r, 4
+}
+
+// formatValue returns v as Write writes it, so that it is parsed back
+// unchanged. If the dialect has escapes, v is escaped as a value of the
+// .properties files. Otherwise, if the dialect supports double quotation
+// marks, a value which contains a separator, a comment prefix or a control
+// character, which has leading or trailing whitespace or which starts with
+// a quotation mark is quoted and escaped. Otherwise only the comment
+// prefixes which would start an inline comment are escaped.
+func (d *Dialect) formatValue(v string) string {
+	if d.Escapes {
+		return escapeProperties(v, false)
+	}
+	if strings.IndexByte(d.Quotes, '"') >= 0 && d.needsQuotes(v) {
+		return d.quote(v)
+	}
+	if d.InlineComments {
+		return escapeComment(v, d.inlineCommentPrefixes())
+	}
+	return v
+}
+
+// needsQuotes reports whether v has to be quoted to be parsed back
+// unchanged
+func (d *Dialect) needsQuotes(v string) bool {
+	if v == "" {
+		return false
+	}
+	if strings.IndexByte(d.Quotes, v[0]) >= 0 || strings.TrimSpace(v) != v {
+		return true
+	}
+	if strings.Contains(v, d.LineSeparator) || (d.Expand && strings.IndexByte(v, '$') >= 0) {
+		return true
+	}
+	for _, sep := range d.KeyValueSeparators {
+		if sep != "" && strings.Contains(v, sep) {
+			return true
+		}
+	}
+	for _, prefix := range d.inlineCommentPrefixes() {
+		if prefix != "" && strings.Contains(v, prefix) {
+			return true
+		}
+	}
+	for i := 0; i < len(v); i++ {
+		if v[i] < ' ' || v[i] == 0x7f {
+			return true
+		}
+	}
+	return false
+}
+
+// quote encloses v in double quotation marks, escaping the quotation marks,
+// the backslashes, the control characters and the line separator, and the
+// dollar signs if the dialect expands the references.
+func (d *Dialect) quote(v string) string {
+	// Escaping every occurrence of the first rune of the line separator
+	// makes sure that the line is not split
+	sep, _ := utf8.DecodeRuneInString(d.LineSeparator)
+
+	var b strings.Builder
+	b.Grow(len(v) + 2)
+	b.WriteByte('"')
+	for i, r := range v {
+		switch {
+		case r == '"' || r == '\\' || (r == '$' && d.Expand):
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == utf8.RuneError:
+			// Keep the invalid UTF-8 bytes
+			_, size := utf8.DecodeRuneInString(v[i:])
+			b.WriteString(v[i : i+size])
+		case r < ' ' || r == 0x7f || r == sep:
+			writeRuneEscape(&b, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// writeRuneEscape writes the \uXXXX escape sequence of r, which is a UTF-16
+// surrogate pair if r is out of the Basic Multilingual Plane
+func writeRuneEscape(b *strings.Builder, r rune) {
+	const hex = "0123456789abcdef"
+	write := func(r rune) {
+		b.WriteString(`\u`)
+		for shift := 12; shift >= 0; shift -= 4 {
+			b.WriteByte(hex[r>>uint(shift)&0xf])
+		}
+	}
+	if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+		write(r1)
+		write(r2)
+		return
+	}
+	write(r)
+}
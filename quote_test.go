@@ -0,0 +1,122 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestQuotedValues(t *testing.T) {
+	raw := []byte(`a = "x y"
+b = 'x y'
+c = "tab\there\nnew line"
+d = "say \"hi\" \\ bye"
+e = 'no \n escape'
+f = "\u00e9\u4e2d\ud83d\ude00"
+g = "unknown \q \u12"
+h = "unmatched'
+i = "x" y "z"
+j = ""
+k = not "quoted"
+`)
+	ini := New()
+	ini.SetTrimQuotes(true)
+	err := ini.Parse(raw, "\n", "=")
+	assert.Equal(t, nil, err)
+
+	tests := []struct {
+		key, value string
+	}{
+		{"a", "x y"},
+		{"b", "x y"},
+		{"c", "tab\there\nnew line"},
+		{"d", `say "hi" \ bye`},
+		{"e", `no \n escape`},
+		{"f", "é中😀"},
+		{"g", `unknown \q \u12`},
+		{"h", `"unmatched'`},
+		{"i", `"x" y "z"`},
+		{"j", ""},
+		{"k", `not "quoted"`},
+	}
+	for _, test := range tests {
+		v, ok := ini.Get(test.key)
+		assert.Equal(t, ok, true)
+		assert.Equal(t, v, test.value)
+	}
+}
+
+func TestWriteQuotesValues(t *testing.T) {
+	ini := New()
+	ini.SetTrimQuotes(true)
+	ini.Set("plain", `C:\path`)
+	ini.Set("space", " padded ")
+	ini.Set("newline", "a\nb")
+	ini.Set("sep", "a=b")
+	ini.Set("comment", "x ; y")
+	ini.Set("quote", `"x"`)
+	ini.Set("control", "\x00\x7f")
+	assert.Equal(t, writeString(t, ini), `plain=C:\path
+space=" padded "
+newline="a\nb"
+sep="a=b"
+comment="x ; y"
+quote="\"x\""
+control="\u0000\u007f"
+`)
+}
+
+func TestQuotedValuesRoundTrip(t *testing.T) {
+	values := []string{
+		"",
+		"plain",
+		" leading and trailing ",
+		"\ttabs\t",
+		"multi\nline\r\nvalue",
+		`back\slash\`,
+		`"double" and 'single'`,
+		"'single'",
+		"a||b|||c|",
+		"key:value",
+		"x ; y # z",
+		"; comment",
+		"#fff",
+		"\\u0041",
+		"é中😀\x00\x1f\x7f",
+		"invalid \xff utf-8",
+	}
+
+	for _, d := range []Dialect{DefaultDialect, WindowsDialect, PHPDialect} {
+		d.LineSeparator = "||"
+		d.KeyValueSeparators = []string{":"}
+		d.Quotes = `"'`
+		d.InlineComments = true
+
+		ini := New()
+		ini.SetDialect(d)
+		for i, v := range values {
+			ini.SectionSet("s", string(rune('a'+i)), v)
+		}
+
+		c := New()
+		c.SetDialect(d)
+		err := c.Parse([]byte(writeString(t, ini)), "", "")
+		assert.Equal(t, nil, err)
+		for i, v := range values {
+			got, ok := c.SectionGet("s", string(rune('a'+i)))
+			assert.Equal(t, ok, true)
+			assert.Equal(t, got, v)
+		}
+	}
+}
+
+func TestWriteWithoutDoubleQuotes(t *testing.T) {
+	ini := New()
+	ini.SetDialect(Dialect{Quotes: "'", InlineComments: true})
+	ini.Set("a", " x ; y ")
+	assert.Equal(t, writeString(t, ini), "a= x \\; y \n")
+}
@@ -0,0 +1,98 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// RecordReader reads a sequence of records from an io.Reader, each of
+// them being INI data like "a:av||b:bv", and parses them one by one into
+// the same INI. e.g. :
+//
+//	rr := goini.NewRecordReader(r, "\n", "||", ":")
+//	for rr.Next() {
+//		v, _ := rr.INI().Get("a")
+//		...
+//	}
+//	if err := rr.Err(); err != nil {
+//		...
+//	}
+type RecordReader struct {
+	scanner *bufio.Scanner
+	ini     *INI
+	lineSep string
+	kvSep   string
+	record  int
+	err     error
+}
+
+// NewRecordReader returns a RecordReader reading the records separated by
+// recordSep from r. The records are parsed as Parse does with lineSep and
+// kvSep. If recordSep is empty, no record is read and Err returns an error.
+func NewRecordReader(r io.Reader, recordSep, lineSep, kvSep string) *RecordReader {
+	scanner, _ := newScanner(r, recordSep)
+	rr := &RecordReader{
+		scanner: scanner,
+		ini:     New(),
+		lineSep: lineSep,
+		kvSep:   kvSep,
+	}
+	if recordSep == "" {
+		rr.err = errors.New("The record separator is empty")
+	}
+	return rr
+}
+
+// Next parses the next record, which INI returns then. Blank records are
+// skipped. It returns false at the end of the data, or if an error occurs
+// which Err returns.
+func (rr *RecordReader) Next() bool {
+	if rr.err != nil {
+		return false
+	}
+	for rr.scanner.Scan() {
+		rr.record++
+		data := rr.scanner.Bytes()
+		if len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+
+		rr.ini.Reset()
+		if err := rr.ini.Parse(data, rr.lineSep, rr.kvSep); err != nil {
+			rr.err = errors.New("Record #" + strconv.Itoa(rr.record) + " : " + err.Error())
+			return false
+		}
+		return true
+	}
+	rr.err = rr.scanner.Err()
+	return false
+}
+
+// INI returns the INI holding the record parsed by the last call to Next.
+// The same INI is reused for every record, so it is only valid until the
+// next call to Next. Its settings, e.g. SetTrimQuotes or SetDialect, are
+// kept for all the records. With SetZeroCopy, no memory is allocated per
+// record once the keys are known, and the values are only valid until the
+// next call to Next too.
+func (rr *RecordReader) INI() *INI {
+	return rr.ini
+}
+
+// Record returns the number of the record parsed by the last call to
+// Next, counted from 1 including the blank records
+func (rr *RecordReader) Record() int {
+	return rr.record
+}
+
+// Err returns the first error encountered by Next, or nil at the end of
+// the data
+func (rr *RecordReader) Err() error {
+	return rr.err
+}
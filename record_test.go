@@ -0,0 +1,70 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestRecordReader(t *testing.T) {
+	raw := "a:1||b:x\n\na:2||c:'y'\na:3\n"
+	rr := NewRecordReader(iotest.OneByteReader(strings.NewReader(raw)), "\n", "||", ":")
+	rr.INI().SetTrimQuotes(true)
+
+	var got []string
+	var records []int
+	for rr.Next() {
+		ini := rr.INI()
+		a, _ := ini.Get("a")
+		_, hasB := ini.Get("b")
+		c, _ := ini.Get("c")
+		got = append(got, a+"/"+strconv.FormatBool(hasB)+"/"+c)
+		records = append(records, rr.Record())
+	}
+	assert.Equal(t, nil, rr.Err())
+	assert.Equal(t, got, []string{"1/true/", "2/false/y", "3/false/"})
+	assert.Equal(t, records, []int{1, 3, 4})
+	assert.Equal(t, rr.Next(), false)
+}
+
+func TestRecordReaderSeparators(t *testing.T) {
+	raw := "[s]\r\nk=1@@@[s]\r\nk=2@@@"
+	rr := NewRecordReader(strings.NewReader(raw), "@@@", "\r\n", "=")
+	rr.INI().SetParseSection(true)
+
+	var got []string
+	for rr.Next() {
+		v, _ := rr.INI().SectionGet("s", "k")
+		got = append(got, v)
+	}
+	assert.Equal(t, nil, rr.Err())
+	assert.Equal(t, got, []string{"1", "2"})
+}
+
+func TestRecordReaderError(t *testing.T) {
+	rr := NewRecordReader(strings.NewReader("a:1\nbad\na:3"), "\n", "||", ":")
+	assert.Equal(t, rr.Next(), true)
+	assert.Equal(t, rr.Next(), false)
+	assert.NotEqual(t, nil, rr.Err())
+	assert.Equal(t, strings.HasPrefix(rr.Err().Error(), "Record #2 : "), true)
+	assert.Equal(t, rr.Next(), false)
+
+	readErr := errors.New("read error")
+	rr = NewRecordReader(io.MultiReader(strings.NewReader("a:1\n"), iotest.ErrReader(readErr)), "\n", "||", ":")
+	assert.Equal(t, rr.Next(), true)
+	assert.Equal(t, rr.Next(), false)
+	assert.Equal(t, rr.Err(), readErr)
+
+	rr = NewRecordReader(strings.NewReader("a:1\na:2"), "", "||", ":")
+	assert.Equal(t, rr.Next(), false)
+	assert.NotEqual(t, nil, rr.Err())
+}
@@ -0,0 +1,79 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"strings"
+	"unsafe"
+)
+
+// maxInterned is the maximum number of names an INI interns
+const maxInterned = 4096
+
+// SetZeroCopy sets whether Parse stores the values without copying them,
+// as strings referring to the data given to Parse. It saves an allocation
+// for every value, but the data must not be modified as long as the INI
+// or any of its values is in use. ParseFrom and ParseFile always copy,
+// and so do Clone and Freeze. Together with Reset, which then reuses the
+// maps of the sections, it makes parsing many records of the same keys
+// allocation free, see RecordReader.
+func (ini *INI) SetZeroCopy(v bool) {
+	ini.zeroCopy = v
+}
+
+// internName returns the parsed section name or key b as a string. The
+// names are interned, so parsing the same names again, e.g. after Reset,
+// does not allocate them again.
+func (ini *INI) internName(b []byte) string {
+	if s, ok := ini.interned[string(b)]; ok {
+		return s
+	}
+	s := string(b)
+	if len(ini.interned) < maxInterned {
+		if ini.interned == nil {
+			ini.interned = make(map[string]string)
+		}
+		ini.interned[s] = s
+	}
+	return s
+}
+
+// parseCache is the dialect of the last parse, which is reused until the
+// settings are changed
+type parseCache struct {
+	lineSep string // The separators given to parseDialect
+	kvSep   string
+	d       *Dialect
+	sep     []byte // The line separator of d
+}
+
+// cachedParseDialect is like parseDialect, but reuses the dialect of the
+// last parse if possible. The setters of the settings clear the cache.
+func (ini *INI) cachedParseDialect(lineSep, kvSep string) (*Dialect, []byte) {
+	c := ini.cache
+	if c == nil || c.lineSep != lineSep || c.kvSep != kvSep {
+		d := ini.parseDialect(lineSep, kvSep)
+		c = &parseCache{lineSep, kvSep, d, []byte(d.LineSeparator)}
+		ini.cache = c
+	}
+	return c.d, c.sep
+}
+
+// ownedValue returns the value v, copied if it may refer to the data given
+// to Parse, so that it stays valid when the data are modified
+func (ini *INI) ownedValue(v string) string {
+	if ini.borrowed {
+		return strings.Clone(v)
+	}
+	return v
+}
+
+// unsafeString returns a string sharing the memory of b
+func unsafeString(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	return unsafe.String(unsafe.SliceData(b), len(b))
+}
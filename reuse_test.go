@@ -0,0 +1,142 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestParseAllocs(t *testing.T) {
+	raw := []byte("a:1||b:True||c:true||||d:off||e:on||f:false||g:0||||||")
+	ini := New()
+	ini.SetZeroCopy(true)
+	allocs := testing.AllocsPerRun(100, func() {
+		ini.Reset()
+		ini.Parse(raw, "||", ":")
+	})
+	assert.Equal(t, allocs, float64(0))
+}
+
+func TestResetReusesMemory(t *testing.T) {
+	ini := New()
+	ini.SetParseSection(true)
+	err := ini.Parse([]byte("a=1\n[s]\nb=2\n[t]\nc=3\n"), "\n", "=")
+	assert.Equal(t, nil, err)
+
+	ini.Reset()
+	assert.Equal(t, len(ini.GetAll()), 0)
+	assert.Equal(t, ini.HasSection("s"), false)
+	assert.Equal(t, writeString(t, ini), "")
+
+	err = ini.Parse([]byte("[t]\nd=4\n[s]\nb=5\n"), "\n", "=")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, writeString(t, ini), "[t]\nd=4\n[s]\nb=5\n")
+	assert.Equal(t, ini.Line("s", "b"), 4)
+	assert.Equal(t, ini.Line("t", "c"), 0)
+
+	ini.Reset()
+	ini.SectionSet("u", "x", "1")
+	ini.SectionSet("s", "y", "2")
+	assert.Equal(t, writeString(t, ini), "[u]\nx=1\n[s]\ny=2\n")
+}
+
+func TestResetKeepsReturnedMaps(t *testing.T) {
+	ini := New()
+	err := ini.Parse([]byte("a:1||b:2"), "||", ":")
+	assert.Equal(t, nil, err)
+	kv, _ := ini.GetKvmap("")
+	ini.Reset()
+	assert.Equal(t, kv, Kvmap{"a": "1", "b": "2"})
+
+	// With zero copy the maps are reused, and so are the key lists
+	ini.SetZeroCopy(true)
+	ini.SetParseSection(true)
+	for i := 0; i < 10; i++ {
+		ini.Reset()
+		err = ini.Parse([]byte("[s"+strconv.Itoa(i)+"]\na=1"), "\n", "=")
+		assert.Equal(t, nil, err)
+	}
+	assert.Equal(t, len(ini.keyNames) <= 2, true)
+	assert.Equal(t, len(ini.free)+len(ini.freeKeys) <= 2, true)
+}
+
+func TestParseAgainReplacesDefaultSection(t *testing.T) {
+	ini := New()
+	err := ini.Parse([]byte("a:1||b:2"), "||", ":")
+	assert.Equal(t, nil, err)
+	err = ini.Parse([]byte("c:3"), "||", ":")
+	assert.Equal(t, nil, err)
+	_, ok := ini.Get("a")
+	assert.Equal(t, ok, false)
+	assert.Equal(t, writeString(t, ini), "c:3||")
+}
+
+func TestZeroCopy(t *testing.T) {
+	raw := []byte(`a:1||b:"x\ty"`)
+	ini := New()
+	ini.SetZeroCopy(true)
+	ini.SetTrimQuotes(true)
+	err := ini.Parse(raw, "||", ":")
+	assert.Equal(t, nil, err)
+
+	// The values refer to the data, except the unescaped ones
+	raw[2] = '9'
+	raw[len(raw)-2] = 'z'
+	v, _ := ini.Get("a")
+	assert.Equal(t, v, "9")
+	v, _ = ini.Get("b")
+	assert.Equal(t, v, "x\ty")
+
+	// The keys never refer to the data
+	raw[0] = 'z'
+	_, ok := ini.Get("a")
+	assert.Equal(t, ok, true)
+
+	// Neither do the values of a clone or a snapshot
+	c, f := ini.Clone(), ini.Freeze()
+	raw[2] = '8'
+	v, _ = c.Get("a")
+	assert.Equal(t, v, "9")
+	v, _ = f.Get("a")
+	assert.Equal(t, v, "9")
+	v, _ = ini.Get("a")
+	assert.Equal(t, v, "8")
+}
+
+func TestSettingsClearParseCache(t *testing.T) {
+	ini := New()
+	err := ini.Parse([]byte("a='1'\n[s]\nb=2"), "\n", "=")
+	assert.NotEqual(t, nil, err)
+
+	ini.SetParseSection(true)
+	ini.SetTrimQuotes(true)
+	err = ini.Parse([]byte("a='1'\n[s]\nb=2"), "\n", "=")
+	assert.Equal(t, nil, err)
+	v, _ := ini.Get("a")
+	assert.Equal(t, v, "1")
+
+	ini.SetCaseInsensitive(true, true)
+	ini.SetInlineComments(true)
+	err = ini.Parse([]byte("[S]\nB=3 ; three"), "\n", "=")
+	assert.Equal(t, nil, err)
+	v, _ = ini.SectionGet("s", "b")
+	assert.Equal(t, v, "3")
+}
+
+func TestInternBound(t *testing.T) {
+	ini := New()
+	var raw strings.Builder
+	for i := 0; i < maxInterned+10; i++ {
+		raw.WriteString("k" + strconv.Itoa(i) + "=v\n")
+	}
+	err := ini.Parse([]byte(raw.String()), "\n", "=")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, len(ini.interned), maxInterned)
+	assert.Equal(t, len(ini.GetAll()[""]), maxInterned+10)
+}
@@ -0,0 +1,369 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ValueType is the type of a value declared in a Schema
+type ValueType string
+
+const (
+	TypeString   ValueType = "string"
+	TypeInt      ValueType = "int"
+	TypeFloat    ValueType = "float"
+	TypeBool     ValueType = "bool"     // See GetBool for the accepted values
+	TypeDuration ValueType = "duration" // Parsed by time.ParseDuration
+	TypeURL      ValueType = "url"      // An absolute URL with a scheme and a host
+)
+
+// KeySchema declares a key of a section.
+type KeySchema struct {
+	Name        string    `json:"name"`
+	Type        ValueType `json:"type,omitempty"` // Empty means TypeString
+	Required    bool      `json:"required,omitempty"`
+	Default     string    `json:"default,omitempty"` // Used by ApplyDefaults if not empty
+	Description string    `json:"description,omitempty"`
+
+	// Min and Max are the inclusive range of an int, float or duration
+	// value. An empty string means unlimited.
+	Min string `json:"min,omitempty"`
+	Max string `json:"max,omitempty"`
+
+	Enum    []string `json:"enum,omitempty"`    // The allowed values if not empty
+	Pattern string   `json:"pattern,omitempty"` // A regular expression which the whole value must match
+}
+
+// SectionSchema declares a section and its keys.
+// The default section is named DefaultSection.
+type SectionSchema struct {
+	Name        string      `json:"name"`
+	Required    bool        `json:"required,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Keys        []KeySchema `json:"keys,omitempty"`
+}
+
+// Schema declares the sections and keys of INI documents.
+// It can be declared in Go, or loaded from a JSON or INI file by
+// LoadSchemaFile. A Schema must not be copied after its first use.
+type Schema struct {
+	Sections []SectionSchema `json:"sections"`
+
+	// Strict reports the sections and keys which are not declared as
+	// violations, suggesting the most similar declared name.
+	Strict bool `json:"strict,omitempty"`
+
+	patterns sync.Map // The compiled patterns of the keys, by Pattern
+}
+
+// Violation is a problem found by Schema.Validate
+type Violation struct {
+	Section string
+	Key     string // Empty if the violation is about the section
+	Line    int    // The line in the parsed data, or 0 if unknown
+	Message string
+}
+
+func (v Violation) String() string {
+	var s string
+	if v.Line > 0 {
+		s = "line " + strconv.Itoa(v.Line) + ": "
+	}
+	s += "[" + v.Section + "]"
+	if v.Key != "" {
+		s += " " + v.Key
+	}
+	return s + " " + v.Message
+}
+
+// ValidationError holds all the violations found by Schema.Validate
+type ValidationError []Violation
+
+func (e ValidationError) Error() string {
+	lines := make([]string, len(e))
+	for i, v := range e {
+		lines[i] = v.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Validate checks ini against the schema. It returns nil if ini is valid,
+// otherwise a ValidationError holding every violation.
+// Sections and keys which are not declared in the schema are ignored
+// unless the schema is strict.
+func (s *Schema) Validate(ini *INI) error {
+	var violations ValidationError
+	known := make(knownKeys)
+	for _, ss := range s.Sections {
+		known.add(ss.Name, "")
+		for _, ks := range ss.Keys {
+			known.add(ss.Name, ks.Name)
+		}
+
+		// The keys of an optional section are only checked if it exists,
+		// while the default section always does
+		if ss.Name != DefaultSection && !ini.HasSection(ss.Name) {
+			if ss.Required {
+				violations = append(violations, Violation{Section: ss.Name, Message: "is required"})
+			}
+			continue
+		}
+
+		for _, ks := range ss.Keys {
+			v, ok := ini.SectionGet(ss.Name, ks.Name)
+			if !ok {
+				if ks.Required {
+					violations = append(violations, Violation{
+						Section: ss.Name,
+						Key:     ks.Name,
+						Line:    ini.SectionLine(ss.Name),
+						Message: "is required"})
+				}
+				continue
+			}
+			if err := ks.check(v, &s.patterns); err != nil {
+				violations = append(violations, Violation{
+					Section: ss.Name,
+					Key:     ks.Name,
+					Line:    ini.Line(ss.Name, ks.Name),
+					Message: err.Error()})
+			}
+		}
+	}
+
+	if s.Strict {
+		violations = append(violations, known.unknown(ini)...)
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return violations
+}
+
+// ApplyDefaults stores the default value of every key which is declared
+// with a default value but does not exist in ini. As Validate does, it
+// skips the sections which do not exist, except the default section.
+func (s *Schema) ApplyDefaults(ini *INI) {
+	for _, ss := range s.Sections {
+		if ss.Name != DefaultSection && !ini.HasSection(ss.Name) {
+			continue
+		}
+		for _, ks := range ss.Keys {
+			if ks.Default == "" || ini.HasKey(ss.Name, ks.Name) {
+				continue
+			}
+			ini.SectionSet(ss.Name, ks.Name, ks.Default)
+		}
+	}
+}
+
+// check checks the value v of the key. The pattern is compiled once and
+// cached in patterns.
+func (ks *KeySchema) check(v string, patterns *sync.Map) error {
+	if len(ks.Enum) > 0 {
+		found := false
+		for _, e := range ks.Enum {
+			if v == e {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.New("must be one of " + strings.Join(ks.Enum, ", ") + " but is " + strconv.Quote(v))
+		}
+	}
+
+	if ks.Pattern != "" {
+		if err := ks.checkPattern(v, patterns); err != nil {
+			return err
+		}
+	}
+
+	switch ks.Type {
+	case TypeString, "":
+		return nil
+	case TypeInt:
+		if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+			return errors.New("must be an integer but is " + strconv.Quote(v))
+		}
+		return checkRange(ks, v, func(s string) (int64, error) {
+			return strconv.ParseInt(s, 10, 64)
+		})
+	case TypeFloat:
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			return errors.New("must be a number but is " + strconv.Quote(v))
+		}
+		return checkRange(ks, v, func(s string) (float64, error) {
+			return strconv.ParseFloat(s, 64)
+		})
+	case TypeBool:
+		if _, ok := parseBool(v); !ok {
+			return errors.New("must be a boolean but is " + strconv.Quote(v))
+		}
+		return nil
+	case TypeDuration:
+		if _, err := time.ParseDuration(v); err != nil {
+			return errors.New("must be a duration but is " + strconv.Quote(v))
+		}
+		return checkRange(ks, v, func(s string) (int64, error) {
+			d, err := time.ParseDuration(s)
+			return int64(d), err
+		})
+	case TypeURL:
+		u, err := url.Parse(v)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return errors.New("must be an absolute URL but is " + strconv.Quote(v))
+		}
+		return nil
+	}
+	return errors.New("has an unknown type " + string(ks.Type))
+}
+
+// checkPattern checks whether the whole value v matches the pattern,
+// which is compiled by the first check and then loaded from patterns
+func (ks *KeySchema) checkPattern(v string, patterns *sync.Map) error {
+	re, ok := patterns.Load(ks.Pattern)
+	if !ok {
+		compiled, err := regexp.Compile("^(?:" + ks.Pattern + ")$")
+		if err != nil {
+			return errors.New("has an invalid pattern : " + err.Error())
+		}
+		re, _ = patterns.LoadOrStore(ks.Pattern, compiled)
+	}
+	if !re.(*regexp.Regexp).MatchString(v) {
+		return errors.New("must match " + ks.Pattern + " but is " + strconv.Quote(v))
+	}
+	return nil
+}
+
+// checkRange checks whether v is in [Min, Max] of ks. parse converts a
+// value of the key type to a comparable number.
+func checkRange[N int64 | float64](ks *KeySchema, v string, parse func(string) (N, error)) error {
+	n, _ := parse(v)
+	if ks.Min != "" {
+		min, err := parse(ks.Min)
+		if err != nil {
+			return errors.New("has an invalid min " + ks.Min)
+		}
+		if n < min {
+			return errors.New("must be >= " + ks.Min + " but is " + v)
+		}
+	}
+	if ks.Max != "" {
+		max, err := parse(ks.Max)
+		if err != nil {
+			return errors.New("has an invalid max " + ks.Max)
+		}
+		if n > max {
+			return errors.New("must be <= " + ks.Max + " but is " + v)
+		}
+	}
+	return nil
+}
+
+// LoadSchemaFile loads a Schema from a JSON file (with the extension
+// .json) or an INI file. See ParseSchemaINI for the INI format.
+func LoadSchemaFile(filename string) (*Schema, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(filepath.Ext(filename), ".json") {
+		s := &Schema{}
+		if err := json.Unmarshal(data, s); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	return ParseSchemaINI(data)
+}
+
+// ParseSchemaINI parses a Schema declared in INI format. The key strict
+// in the default section makes the Schema strict. The section
+// [name] declares the section name and the section [name:key] declares
+// a key of that section (use [:key] for the default section), e.g. :
+//
+//	[server]
+//	required = true
+//	description = The server settings
+//
+//	[server:port]
+//	type = int
+//	required = true
+//	min = 1
+//	max = 65535
+//
+//	[server:mode]
+//	enum = debug, release
+//	default = release
+//
+// The sections are declared in document order.
+func ParseSchemaINI(data []byte) (*Schema, error) {
+	ini := New()
+	ini.SetParseSection(true)
+	ini.SetSkipCommits(true)
+	if err := ini.Parse(data, DefaultLineSeparator, DefaultKeyValueSeparator); err != nil {
+		return nil, err
+	}
+
+	s := &Schema{}
+	s.Strict, _ = ini.GetBool("strict")
+	index := make(map[string]int) // section name -> position in s.Sections
+	sectionSchema := func(name string) *SectionSchema {
+		i, ok := index[name]
+		if !ok {
+			i = len(s.Sections)
+			index[name] = i
+			s.Sections = append(s.Sections, SectionSchema{Name: name})
+		}
+		return &s.Sections[i]
+	}
+
+	for _, name := range ini.sectionList() {
+		if name == DefaultSection {
+			continue
+		}
+		kv := ini.sections[name]
+		required, _ := parseBool(kv["required"])
+
+		pos := strings.LastIndex(name, ":")
+		if pos < 0 {
+			ss := sectionSchema(name)
+			ss.Required = required
+			ss.Description = kv["description"]
+			continue
+		}
+
+		ks := KeySchema{
+			Name:        name[pos+1:],
+			Type:        ValueType(kv["type"]),
+			Required:    required,
+			Default:     kv["default"],
+			Description: kv["description"],
+			Min:         kv["min"],
+			Max:         kv["max"],
+			Pattern:     kv["pattern"],
+		}
+		if enum, ok := kv["enum"]; ok {
+			for _, e := range strings.Split(enum, ",") {
+				ks.Enum = append(ks.Enum, strings.TrimSpace(e))
+			}
+		}
+		ss := sectionSchema(name[:pos])
+		ss.Keys = append(ss.Keys, ks)
+	}
+	return s, nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func testSchemaViolations(t *testing.T, schemaFile string) {
+	s, err := LoadSchemaFile(filepath.Join(getTestDataDir(t), schemaFile))
+	assert.Equal(t, nil, err)
+
+	ini := New()
+	err = ini.ParseFile(filepath.Join(getTestDataDir(t), "schema_test.ini"))
+	assert.Equal(t, nil, err)
+
+	err = s.Validate(ini)
+	assert.NotEqual(t, nil, err)
+	violations := err.(ValidationError)
+	assert.Equal(t, len(violations), 4)
+	assert.Equal(t, violations[0], Violation{"", "product", 1, `must match [a-z]+ but is "Test1"`})
+	assert.Equal(t, violations[1], Violation{"server", "port", 4, "must be <= 65535 but is 70000"})
+	assert.Equal(t, violations[2], Violation{"server", "timeout", 5, "must be >= 1s but is 10ms"})
+	assert.Equal(t, violations[3], Violation{"server", "homepage", 6, `must be an absolute URL but is "www.example.com"`})
+	assert.Equal(t, err.Error(), "line 1: [] product must match [a-z]+ but is \"Test1\"\n"+
+		"line 4: [server] port must be <= 65535 but is 70000\n"+
+		"line 5: [server] timeout must be >= 1s but is 10ms\n"+
+		"line 6: [server] homepage must be an absolute URL but is \"www.example.com\"")
+
+	ini.Set("product", "test")
+	ini.SectionSetInt("server", "port", 8080)
+	ini.SectionSet("server", "timeout", "1m")
+	ini.SectionSet("server", "homepage", "http://www.example.com/")
+	assert.Equal(t, nil, s.Validate(ini))
+
+	s.ApplyDefaults(ini)
+	v, _ := ini.SectionGet("server", "mode")
+	assert.Equal(t, v, "release")
+	b, ok := ini.GetBool("debug")
+	assert.Equal(t, b, false)
+	assert.Equal(t, ok, true)
+
+	ini.SectionSet("server", "mode", "test")
+	ini.Delete("server", "port")
+	err = s.Validate(ini)
+	assert.Equal(t, err.Error(), "line 3: [server] port is required\n"+
+		"[server] mode must be one of debug, release but is \"test\"")
+
+	ini.DeleteSection("server")
+	err = s.Validate(ini)
+	assert.Equal(t, err.Error(), "[server] is required")
+}
+
+func TestSchemaINI(t *testing.T) {
+	testSchemaViolations(t, "schema.ini")
+}
+
+func TestSchemaJSON(t *testing.T) {
+	testSchemaViolations(t, "schema.json")
+
+	s1, err := LoadSchemaFile(filepath.Join(getTestDataDir(t), "schema.ini"))
+	assert.Equal(t, nil, err)
+	s2, err := LoadSchemaFile(filepath.Join(getTestDataDir(t), "schema.json"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, s1, s2)
+}
+
+func TestSchemaTypes(t *testing.T) {
+	s := &Schema{Sections: []SectionSchema{{
+		Name: DefaultSection,
+		Keys: []KeySchema{
+			{Name: "i", Type: TypeInt, Min: "-1"},
+			{Name: "f", Type: TypeFloat, Max: "1.5"},
+			{Name: "b", Type: TypeBool},
+			{Name: "d", Type: TypeDuration},
+			{Name: "x", Type: "complex"},
+		},
+	}}}
+
+	ini := New()
+	err := ini.Parse([]byte("i:-2||f:1.6||b:maybe||d:1||x:1"), "||", ":")
+	assert.Equal(t, nil, err)
+	violations := s.Validate(ini).(ValidationError)
+	assert.Equal(t, len(violations), 5)
+	assert.Equal(t, violations[0].Message, "must be >= -1 but is -2")
+	assert.Equal(t, violations[1].Message, "must be <= 1.5 but is 1.6")
+	assert.Equal(t, violations[2].Message, `must be a boolean but is "maybe"`)
+	assert.Equal(t, violations[3].Message, `must be a duration but is "1"`)
+	assert.Equal(t, violations[4].Message, "has an unknown type complex")
+
+	err = ini.Parse([]byte("i:x||f:y||b:on||d:1s||x:1"), "||", ":")
+	assert.Equal(t, nil, err)
+	violations = s.Validate(ini).(ValidationError)
+	assert.Equal(t, len(violations), 3)
+	assert.Equal(t, violations[0].Message, `must be an integer but is "x"`)
+	assert.Equal(t, violations[1].Message, `must be a number but is "y"`)
+
+	// The integers are compared exactly, beyond the precision of a float64
+	s = &Schema{Sections: []SectionSchema{{
+		Name: DefaultSection,
+		Keys: []KeySchema{{Name: "i", Type: TypeInt, Min: "9007199254740993"}},
+	}}}
+	err = ini.Parse([]byte("i:9007199254740992"), "||", ":")
+	assert.Equal(t, nil, err)
+	err = s.Validate(ini)
+	assert.Equal(t, err.Error(), "line 1: [] i must be >= 9007199254740993 but is 9007199254740992")
+}
+
+func TestSchemaFileError(t *testing.T) {
+	_, err := LoadSchemaFile("the/path/to/a/nonexist/schema.json")
+	assert.NotEqual(t, nil, err)
+	_, err = LoadSchemaFile(filepath.Join(getTestDataDir(t), "error.ini"))
+	assert.NotEqual(t, nil, err)
+}
+
+func TestSchemaOptionalSection(t *testing.T) {
+	s := &Schema{Sections: []SectionSchema{
+		{Name: DefaultSection, Keys: []KeySchema{{Name: "name", Required: true}}},
+		{Name: "tls", Keys: []KeySchema{{Name: "cert", Required: true}, {Name: "port", Default: "443"}}},
+	}}
+
+	ini := New()
+	err := s.Validate(ini)
+	assert.Equal(t, err.Error(), "[] name is required")
+
+	ini.Set("name", "goini")
+	s.ApplyDefaults(ini)
+	assert.Equal(t, ini.HasSection("tls"), false)
+	assert.Equal(t, nil, s.Validate(ini))
+
+	ini.SectionSet("tls", "key", "k.pem")
+	err = s.Validate(ini)
+	assert.Equal(t, err.Error(), "[tls] cert is required")
+}
@@ -0,0 +1,165 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"errors"
+)
+
+// HasSection reports whether the section exists
+func (ini *INI) HasSection(section string) bool {
+	_, ok := ini.sections[ini.sectionName(section)]
+	return ok
+}
+
+// HasKey reports whether the key exists in the section
+func (ini *INI) HasKey(section, key string) bool {
+	_, ok := ini.SectionGet(section, key)
+	return ok
+}
+
+// DeleteSection deletes the section and all its keys.
+func (ini *INI) DeleteSection(section string) {
+	section = ini.sectionName(section)
+	kv, ok := ini.sections[section]
+	if !ok {
+		return
+	}
+
+	var changes []change
+	for _, key := range ini.keyList(section) {
+		changes = append(changes, change{section, key, kv[key], "", true})
+	}
+	for _, key := range ini.keyList(section) {
+		delete(ini.keyLines, lineKey{section, key})
+		delete(ini.keySpelling, lineKey{section, key})
+		delete(ini.comments, lineKey{section, key})
+	}
+	delete(ini.sectionSpelling, section)
+	delete(ini.sections, section)
+	delete(ini.keyNames, section)
+	delete(ini.sectionLines, section)
+	ini.sectionNames = removeName(ini.sectionNames, section)
+	ini.notify(changes)
+}
+
+// RenameSection renames the section from to the section to,
+// keeping its position in the document.
+// It fails if from does not exist or to already exists. If the section
+// names are case insensitive, it may change the spelling of a section.
+func (ini *INI) RenameSection(from, to string) error {
+	spelling := to
+	from, to = ini.sectionName(from), ini.sectionName(to)
+	kv, ok := ini.sections[from]
+	if !ok {
+		return errors.New("Section [" + from + "] does not exist")
+	}
+	if from == to {
+		ini.recordSectionSpelling(to, spelling)
+		return nil
+	}
+	if _, ok := ini.sections[to]; ok {
+		return errors.New("Section [" + to + "] already exists")
+	}
+
+	keys := ini.keyList(from)
+	ini.sections[to] = kv
+	ini.keyNames[to] = keys
+	delete(ini.sections, from)
+	delete(ini.keyNames, from)
+	moveEntry(ini.sectionLines, from, to)
+	delete(ini.sectionSpelling, from)
+	ini.recordSectionSpelling(to, spelling)
+	for _, key := range keys {
+		moveEntry(ini.keyLines, lineKey{from, key}, lineKey{to, key})
+		moveEntry(ini.comments, lineKey{from, key}, lineKey{to, key})
+		if s, ok := ini.keySpelling[lineKey{from, key}]; ok {
+			delete(ini.keySpelling, lineKey{from, key})
+			ini.recordKeySpelling(to, key, s)
+		}
+	}
+	for i, section := range ini.sectionNames {
+		if section == from {
+			ini.sectionNames[i] = to
+		}
+	}
+
+	var changes []change
+	for _, key := range keys {
+		changes = append(changes, change{from, key, kv[key], "", true})
+	}
+	for _, key := range keys {
+		changes = append(changes, change{to, key, "", kv[key], false})
+	}
+	ini.notify(changes)
+	return nil
+}
+
+// CopySection copies all the keys of the section from into a new section
+// to, which is appended to the document.
+// It fails if from does not exist or to already exists.
+func (ini *INI) CopySection(from, to string) error {
+	from = ini.sectionName(from)
+	kv, ok := ini.sections[from]
+	if !ok {
+		return errors.New("Section [" + from + "] does not exist")
+	}
+	if ini.HasSection(to) {
+		return errors.New("Section [" + to + "] already exists")
+	}
+
+	ini.recordSectionSpelling(ini.sectionName(to), to)
+	ini.newSection(ini.sectionName(to))
+	for _, key := range ini.keyList(from) {
+		ini.SectionSet(to, ini.spellKey(from, key), kv[key])
+		ini.setComment(ini.sectionName(to), key, ini.comments[lineKey{from, key}])
+	}
+	return nil
+}
+
+// RenameKey renames the key from to the key to in the section,
+// keeping its position in the section.
+// It fails if from does not exist or to already exists. If the keys are
+// case insensitive, it may change the spelling of a key.
+func (ini *INI) RenameKey(section, from, to string) error {
+	spelling := to
+	section, from, to = ini.sectionName(section), ini.keyName(from), ini.keyName(to)
+	kv := ini.sections[section]
+	value, ok := kv[from]
+	if !ok {
+		return errors.New("[" + section + "] " + from + " does not exist")
+	}
+	if from == to {
+		ini.recordKeySpelling(section, to, spelling)
+		return nil
+	}
+	if _, ok := kv[to]; ok {
+		return errors.New("[" + section + "] " + to + " already exists")
+	}
+
+	keys := ini.keyList(section)
+	for i, key := range keys {
+		if key == from {
+			keys[i] = to
+		}
+	}
+	ini.keyNames[section] = keys
+	kv[to] = value
+	delete(kv, from)
+	moveEntry(ini.keyLines, lineKey{section, from}, lineKey{section, to})
+	moveEntry(ini.comments, lineKey{section, from}, lineKey{section, to})
+	delete(ini.keySpelling, lineKey{section, from})
+	ini.recordKeySpelling(section, to, spelling)
+	ini.notify([]change{{section, from, value, "", true}, {section, to, "", value, false}})
+	return nil
+}
+
+// moveEntry moves the entry recorded for from to to
+func moveEntry[K comparable, V any](m map[K]V, from, to K) {
+	if v, ok := m[from]; ok {
+		m[to] = v
+		delete(m, from)
+	}
+}
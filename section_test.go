@@ -0,0 +1,121 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+const sectionTestData = "z=1\na=2\n[sss]\nc=3\nb=4\n[ddd]\nage=30\n"
+
+// parseTestINI parses data into a new INI, which setup configures first
+func parseTestINI(t *testing.T, data string, setup func(ini *INI)) *INI {
+	ini := New()
+	setup(ini)
+	err := ini.Parse([]byte(data), "", "")
+	assert.Equal(t, nil, err)
+	return ini
+}
+
+func parseSections(ini *INI) {
+	ini.SetParseSection(true)
+}
+
+func writeString(t *testing.T, ini *INI) string {
+	var buf bytes.Buffer
+	err := ini.Write(&buf)
+	assert.Equal(t, nil, err)
+	return buf.String()
+}
+
+func TestWriteKeepsOrder(t *testing.T) {
+	ini := parseTestINI(t, sectionTestData, parseSections)
+	ini.SectionSet("sss", "a", "5")
+	ini.Set("m", "6")
+	ini.SectionSet("eee", "x", "7")
+	ini.Delete("", "z")
+	assert.Equal(t, writeString(t, ini), "a=2\nm=6\n[sss]\nc=3\nb=4\na=5\n[ddd]\nage=30\n[eee]\nx=7\n")
+}
+
+func TestHasSectionHasKey(t *testing.T) {
+	ini := parseTestINI(t, sectionTestData, parseSections)
+	assert.Equal(t, ini.HasSection("sss"), true)
+	assert.Equal(t, ini.HasSection("xxx"), false)
+	assert.Equal(t, ini.HasKey("sss", "c"), true)
+	assert.Equal(t, ini.HasKey("sss", "z"), false)
+	assert.Equal(t, ini.HasKey("xxx", "c"), false)
+}
+
+func TestDeleteSection(t *testing.T) {
+	ini := parseTestINI(t, sectionTestData, parseSections)
+	var removed []string
+	ini.SubscribeSection("sss", func(key, old, new string, deleted bool) {
+		if deleted {
+			removed = append(removed, key+"="+old)
+		}
+	})
+
+	ini.DeleteSection("sss")
+	ini.DeleteSection("sss") // delete again
+	assert.Equal(t, ini.HasSection("sss"), false)
+	assert.Equal(t, removed, []string{"c=3", "b=4"})
+	assert.Equal(t, writeString(t, ini), "z=1\na=2\n[ddd]\nage=30\n")
+
+	ini.SectionSet("sss", "n", "1")
+	assert.Equal(t, writeString(t, ini), "z=1\na=2\n[ddd]\nage=30\n[sss]\nn=1\n")
+}
+
+func TestRenameSection(t *testing.T) {
+	ini := parseTestINI(t, sectionTestData, parseSections)
+	err := ini.RenameSection("sss", "ddd")
+	assert.NotEqual(t, nil, err)
+	err = ini.RenameSection("xxx", "yyy")
+	assert.NotEqual(t, nil, err)
+
+	var calls []string
+	ini.SubscribeSection("ttt", func(key, old, new string, deleted bool) {
+		calls = append(calls, key+"="+new)
+	})
+	err = ini.RenameSection("sss", "ttt")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, calls, []string{"c=3", "b=4"})
+	assert.Equal(t, writeString(t, ini), "z=1\na=2\n[ttt]\nc=3\nb=4\n[ddd]\nage=30\n")
+}
+
+func TestCopySection(t *testing.T) {
+	ini := parseTestINI(t, sectionTestData, parseSections)
+	err := ini.CopySection("sss", "ddd")
+	assert.NotEqual(t, nil, err)
+	err = ini.CopySection("xxx", "yyy")
+	assert.NotEqual(t, nil, err)
+
+	err = ini.CopySection("sss", "ttt")
+	assert.Equal(t, nil, err)
+	ini.SectionSet("ttt", "c", "33")
+	v, _ := ini.SectionGet("sss", "c")
+	assert.Equal(t, v, "3")
+	assert.Equal(t, writeString(t, ini), "z=1\na=2\n[sss]\nc=3\nb=4\n[ddd]\nage=30\n[ttt]\nc=33\nb=4\n")
+}
+
+func TestRenameKey(t *testing.T) {
+	ini := parseTestINI(t, sectionTestData, parseSections)
+	err := ini.RenameKey("sss", "c", "b")
+	assert.NotEqual(t, nil, err)
+	err = ini.RenameKey("sss", "x", "y")
+	assert.NotEqual(t, nil, err)
+	err = ini.RenameKey("xxx", "x", "y")
+	assert.NotEqual(t, nil, err)
+
+	err = ini.RenameKey("sss", "c", "d")
+	assert.Equal(t, nil, err)
+	v, ok := ini.SectionGet("sss", "d")
+	assert.Equal(t, v, "3")
+	assert.Equal(t, ok, true)
+	assert.Equal(t, ini.HasKey("sss", "c"), false)
+	assert.Equal(t, writeString(t, ini), "z=1\na=2\n[sss]\nd=3\nb=4\n[ddd]\nage=30\n")
+}
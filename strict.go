@@ -0,0 +1,133 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"strconv"
+)
+
+// knownKeys holds the sections and keys declared by a struct or a Schema,
+// used to report unknown ones in strict mode
+type knownKeys map[string]map[string]bool
+
+// add declares the key of section. An empty key only declares the section.
+func (k knownKeys) add(section, key string) {
+	keys, ok := k[section]
+	if !ok {
+		keys = make(map[string]bool)
+		k[section] = keys
+	}
+	if key != "" {
+		keys[key] = true
+	}
+}
+
+// unknown returns a Violation for every section and key of ini which is
+// not declared, in document order. The keys of an unknown section are
+// not reported one by one. The InheritedFrom key is always known.
+func (k knownKeys) unknown(ini *INI) []Violation {
+	k = k.fold(ini)
+	var violations []Violation
+	for _, section := range ini.sectionList() {
+		keys, ok := k[section]
+		if !ok {
+			if section == DefaultSection && len(ini.sections[section]) == 0 {
+				continue
+			}
+			violations = append(violations, Violation{
+				Section: ini.spellSection(section),
+				Line:    ini.SectionLine(section),
+				Message: unknownMessage(section, k.sections())})
+			continue
+		}
+
+		for _, key := range ini.keyList(section) {
+			if keys[key] || (section == DefaultSection && key == InheritedFrom) {
+				continue
+			}
+			violations = append(violations, Violation{
+				Section: ini.spellSection(section),
+				Key:     ini.spellKey(section, key),
+				Line:    ini.Line(section, key),
+				Message: unknownMessage(key, keys)})
+		}
+	}
+	return violations
+}
+
+// fold returns the names declared in k as they are stored in ini
+func (k knownKeys) fold(ini *INI) knownKeys {
+	if !ini.foldSections && !ini.foldKeys {
+		return k
+	}
+	folded := make(knownKeys, len(k))
+	for section, keys := range k {
+		section = ini.sectionName(section)
+		folded.add(section, "")
+		for key := range keys {
+			folded[section][ini.keyName(key)] = true
+		}
+	}
+	return folded
+}
+
+func (k knownKeys) sections() map[string]bool {
+	names := make(map[string]bool, len(k))
+	for section := range k {
+		names[section] = true
+	}
+	return names
+}
+
+// unknownMessage returns the message about the unknown name, suggesting the
+// most similar candidate if there is one similar enough
+func unknownMessage(name string, candidates map[string]bool) string {
+	maxDistance := len(name) / 3
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+
+	best, bestDistance := "", maxDistance+1
+	for _, c := range sortedKeys(candidates) {
+		if c == "" {
+			continue
+		}
+		if d := editDistance(name, c); d < bestDistance {
+			best, bestDistance = c, d
+		}
+	}
+	if best == "" {
+		return "is unknown"
+	}
+	return "is unknown, did you mean " + strconv.Quote(best) + "?"
+}
+
+// editDistance returns the edit distance between a and b counted in runes,
+// where an edit is an insertion, a deletion, a substitution or a
+// transposition of two adjacent runes (the optimal string alignment distance)
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
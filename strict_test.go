@@ -0,0 +1,71 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestUnmarshalStrict(t *testing.T) {
+	ini := New()
+	err := ini.ParseFile(filepath.Join(getTestDataDir(t), "strict.ini"))
+	assert.Equal(t, nil, err)
+
+	var cfg struct {
+		Product  string `ini:"product"`
+		RetLimit int    `ini:"ret_limit"`
+		Server   struct {
+			Port int `ini:"port"`
+		} `ini:"server"`
+	}
+	err = ini.Unmarshal(&cfg)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, cfg.Server.Port, 80)
+
+	err = ini.UnmarshalStrict(&cfg)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, err.Error(), "line 2: [] ret_limt is unknown, did you mean \"ret_limit\"?\n"+
+		"line 5: [server] prot is unknown, did you mean \"port\"?\n"+
+		"line 8: [sever] is unknown, did you mean \"server\"?")
+
+	ini.DeleteSection("sever")
+	ini.Delete("", "ret_limt")
+	ini.SectionSet("server", "prot", "1")
+	ini.SectionSet("server", "xyz", "1")
+	err = ini.UnmarshalStrict(&cfg)
+	assert.Equal(t, err.Error(), "line 5: [server] prot is unknown, did you mean \"port\"?\n"+
+		"[server] xyz is unknown")
+}
+
+func TestSchemaStrict(t *testing.T) {
+	s, err := ParseSchemaINI([]byte("strict=true\n[:product]\n[:ret_limit]\ntype=int\n[server:port]\ntype=int\n"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, s.Strict, true)
+
+	ini, err := LoadInheritedINI(filepath.Join(getTestDataDir(t), "strict.ini"))
+	assert.Equal(t, nil, err)
+	err = s.Validate(ini)
+	assert.NotEqual(t, nil, err)
+	violations := err.(ValidationError)
+	assert.Equal(t, len(violations), 3)
+	assert.Equal(t, violations[0], Violation{"", "ret_limt", 2, `is unknown, did you mean "ret_limit"?`})
+	assert.Equal(t, violations[2], Violation{"sever", "", 8, `is unknown, did you mean "server"?`})
+
+	s.Strict = false
+	assert.Equal(t, nil, s.Validate(ini))
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, editDistance("", ""), 0)
+	assert.Equal(t, editDistance("abc", ""), 3)
+	assert.Equal(t, editDistance("", "abc"), 3)
+	assert.Equal(t, editDistance("kitten", "sitting"), 3)
+	assert.Equal(t, editDistance("端口", "端"), 1)
+	assert.Equal(t, editDistance("prot", "port"), 1)
+	assert.Equal(t, unknownMessage("a", map[string]bool{"": true, "bcd": true}), "is unknown")
+}
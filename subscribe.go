@@ -0,0 +1,121 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"sync"
+)
+
+// ChangeFunc is called after the value of a subscribed key has changed.
+// old is "" if the key has just been created. deleted is true if the key
+// has been deleted, in which case new is "".
+type ChangeFunc func(old, new string, deleted bool)
+
+// SectionChangeFunc is called after the value of any key in a subscribed
+// section has changed. See ChangeFunc for the meaning of old, new and
+// deleted.
+type SectionChangeFunc func(key, old, new string, deleted bool)
+
+// change describes the modification of one key
+type change struct {
+	section string
+	key     string
+	old     string
+	new     string
+	deleted bool
+}
+
+type subscription struct {
+	id      uint64
+	section string
+	key     string
+	keyFn   ChangeFunc        // set for a key subscription
+	sectFn  SectionChangeFunc // set for a section subscription
+}
+
+type subscribers struct {
+	mu     sync.Mutex
+	nextID uint64
+	list   []*subscription
+}
+
+// Subscribe registers fn to be called every time the value of key in section
+// is changed by SectionSet (and the other setters), Delete, Merge or Reload.
+// It returns a function which cancels the subscription.
+//
+// The callbacks are called synchronously by the goroutine which modifies
+// the INI, after the modification has been applied. For every change the
+// callbacks are called in the order they were registered, and changes made
+// by one call (e.g. Merge or Reload) are delivered in the order they were
+// applied. No lock is held while a callback runs, so it is free to read or
+// modify the INI and to subscribe or unsubscribe.
+func (ini *INI) Subscribe(section, key string, fn ChangeFunc) (cancel func()) {
+	return ini.subs.add(&subscription{section: ini.sectionName(section), key: ini.keyName(key), keyFn: fn})
+}
+
+// SubscribeSection registers fn to be called every time any key in section
+// is changed. See Subscribe for more detail.
+func (ini *INI) SubscribeSection(section string, fn SectionChangeFunc) (cancel func()) {
+	return ini.subs.add(&subscription{section: ini.sectionName(section), sectFn: fn})
+}
+
+func (s *subscribers) add(sub *subscription) func() {
+	s.mu.Lock()
+	s.nextID++
+	sub.id = s.nextID
+	s.list = append(s.list, sub)
+	s.mu.Unlock()
+
+	return func() {
+		s.remove(sub.id)
+	}
+}
+
+func (s *subscribers) remove(id uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, sub := range s.list {
+		if sub.id == id {
+			// Copy the list so that a notification which is in progress
+			// can keep iterating over its own snapshot.
+			list := make([]*subscription, 0, len(s.list)-1)
+			list = append(list, s.list[:i]...)
+			s.list = append(list, s.list[i+1:]...)
+			return
+		}
+	}
+}
+
+// snapshot returns the current subscriptions. The returned slice is never
+// modified afterwards.
+func (s *subscribers) snapshot() []*subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.list[:len(s.list):len(s.list)]
+}
+
+// notify calls the callbacks interested in changes.
+func (ini *INI) notify(changes []change) {
+	if len(changes) == 0 {
+		return
+	}
+	list := ini.subs.snapshot()
+	if len(list) == 0 {
+		return
+	}
+
+	for _, c := range changes {
+		for _, sub := range list {
+			if sub.section != c.section {
+				continue
+			}
+			if sub.sectFn != nil {
+				sub.sectFn(c.key, c.old, c.new, c.deleted)
+			} else if sub.key == c.key {
+				sub.keyFn(c.old, c.new, c.deleted)
+			}
+		}
+	}
+}
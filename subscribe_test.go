@@ -0,0 +1,148 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestSubscribe(t *testing.T) {
+	ini := New()
+	ini.SectionSet("sss", "a", "aval")
+
+	var calls []string
+	cancel := ini.Subscribe("sss", "a", func(old, new string, deleted bool) {
+		// Reading the INI from a callback must not dead lock
+		v, _ := ini.SectionGet("sss", "a")
+		calls = append(calls, "key:"+old+"->"+new+":"+v+":"+strconv.FormatBool(deleted))
+	})
+	ini.SubscribeSection("sss", func(key, old, new string, deleted bool) {
+		calls = append(calls, "section:"+key+":"+old+"->"+new+":"+strconv.FormatBool(deleted))
+	})
+
+	ini.SectionSet("sss", "a", "aval") // not changed
+	ini.SectionSet("sss", "a", "bval")
+	ini.SectionSet("sss", "b", "x")
+	ini.SectionSet("sss", "e", "")
+	ini.SectionSet("ddd", "a", "x") // other section
+	ini.Delete("sss", "a")
+	ini.Delete("sss", "a") // delete again
+	assert.Equal(t, calls, []string{
+		"key:aval->bval:bval:false",
+		"section:a:aval->bval:false",
+		"section:b:->x:false",
+		"section:e:->:false",
+		"key:bval->::true",
+		"section:a:bval->:true",
+	})
+
+	calls = nil
+	cancel()
+	from := New()
+	from.SectionSet("sss", "a", "merged")
+	ini.Merge(from, false)
+	assert.Equal(t, calls, []string{"section:a:->merged:false"})
+}
+
+func TestSubscribeModifyInCallback(t *testing.T) {
+	ini := New()
+	ini.Subscribe("", "a", func(old, new string, deleted bool) {
+		ini.Set("b", new+new)
+	})
+	var b string
+	ini.Subscribe("", "b", func(old, new string, deleted bool) {
+		b = new
+	})
+	ini.Set("a", "x")
+	assert.Equal(t, b, "xx")
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "reload.ini")
+	err := os.WriteFile(filename, []byte("a=1\nb=2\n[sss]\nc=3\n"), 0644)
+	assert.Equal(t, nil, err)
+
+	ini := New()
+	err = ini.ParseFile(filename)
+	assert.Equal(t, nil, err)
+
+	var calls []string
+	ini.SubscribeSection("", func(key, old, new string, deleted bool) {
+		if deleted {
+			key = "-" + key
+		}
+		calls = append(calls, key+":"+old+"->"+new)
+	})
+	ini.Subscribe("sss", "c", func(old, new string, deleted bool) {
+		calls = append(calls, "c:"+old+"->"+new)
+	})
+
+	err = os.WriteFile(filename, []byte("a=10\nd=4\n[sss]\nc=3\n"), 0644)
+	assert.Equal(t, nil, err)
+	err = ini.Reload()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, calls, []string{"a:1->10", "-b:2->", "d:->4"})
+
+	v, ok := ini.Get("d")
+	assert.Equal(t, v, "4")
+	assert.Equal(t, ok, true)
+
+	err = os.WriteFile(filename, []byte("no key value pair"), 0644)
+	assert.Equal(t, nil, err)
+	err = ini.Reload()
+	assert.NotEqual(t, nil, err)
+	v, ok = ini.Get("a")
+	assert.Equal(t, v, "10")
+	assert.Equal(t, ok, true)
+
+	err = New().Reload()
+	assert.NotEqual(t, nil, err)
+}
+
+func TestReloadInheritedINI(t *testing.T) {
+	filename := filepath.Join(getTestDataDir(t), "project.ini")
+	ini, err := LoadInheritedINI(filename)
+	assert.Equal(t, nil, err)
+
+	called := false
+	ini.SubscribeSection("", func(key, old, new string, deleted bool) {
+		called = true
+	})
+	err = ini.Reload()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, called, false)
+
+	v, ok := ini.Get("version")
+	assert.Equal(t, v, "0.0.0.0")
+	assert.Equal(t, ok, true)
+}
+
+func TestReloadInheritedINIKeepsSettings(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "base.ini"), []byte("[Server]\nport=80\n"), 0644)
+	assert.Equal(t, nil, err)
+	filename := filepath.Join(dir, "app.ini")
+	err = os.WriteFile(filename, []byte("inherited_from=base.ini\nname=\"app\" ; the name\n"), 0644)
+	assert.Equal(t, nil, err)
+
+	ini, err := LoadInheritedINI(filename)
+	assert.Equal(t, nil, err)
+	ini.SetTrimQuotes(true)
+	ini.SetInlineComments(true)
+	ini.SetCaseInsensitive(true, true)
+	err = ini.Reload()
+	assert.Equal(t, nil, err)
+	v, _ := ini.Get("NAME")
+	assert.Equal(t, v, "app")
+	v, ok := ini.SectionGet("server", "PORT")
+	assert.Equal(t, v, "80")
+	assert.Equal(t, ok, true)
+}
@@ -0,0 +1,539 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// WriteTOML writes this INI as a TOML document. The keys of the default
+// section come first, and every other section is a table, of which the
+// dotted name is the path, e.g. [server.http], or [remote.origin] for the
+// section [remote "origin"] with SetSubsections. The values which are integers, floats, true or
+// false are written as such, and the other values as strings. It fails if
+// a key has the path of a table.
+func (ini *INI) WriteTOML(w io.Writer) error {
+	sections := ini.nonEmptySections()
+	keys := make(map[string]bool)
+	for _, section := range sections {
+		path := ini.sectionPath(ini.spellSection(section))
+		ini.pairs(section, func(key, value string) bool {
+			keys[strings.Join(append(path[:len(path):len(path)], key), "\x00")] = true
+			return true
+		})
+	}
+	for _, section := range sections {
+		path := ini.sectionPath(ini.spellSection(section))
+		for i := 1; i <= len(path); i++ {
+			if keys[strings.Join(path[:i], "\x00")] {
+				return errors.New("Section [" + ini.spellSection(section) + "] collides with key " + path[i-1])
+			}
+		}
+	}
+
+	buf := bufio.NewWriter(w)
+	for i, section := range sections {
+		if section != DefaultSection {
+			if i > 0 {
+				buf.WriteByte('\n')
+			}
+			buf.WriteByte('[')
+			for j, name := range ini.sectionPath(ini.spellSection(section)) {
+				if j > 0 {
+					buf.WriteByte('.')
+				}
+				writeTOMLKey(buf, name)
+			}
+			buf.WriteString("]\n")
+		}
+		ini.pairs(section, func(key, value string) bool {
+			writeTOMLKey(buf, key)
+			buf.WriteString(" = ")
+			if value == "true" || value == "false" || isTOMLNumber(value) {
+				buf.WriteString(value)
+			} else {
+				writeBasicString(buf, value)
+			}
+			buf.WriteByte('\n')
+			return true
+		})
+	}
+	return buf.Flush()
+}
+
+// ParseTOML parses the flat subset of TOML written by WriteTOML to store the
+// data in the INI. As Parse does, it replaces the sections it holds.
+//
+// The tables are the sections, named by their dotted paths, and a dotted key
+// is a key of a subsection. The strings are unquoted, the integers are
+// stored in decimal without underscores, the arrays of scalars are stored
+// as comma separated lists, and the other values, such as the dates, are
+// stored as they are written. The multi-line strings and arrays, the inline
+// tables and the arrays of tables are not supported.
+func (ini *INI) ParseTOML(data []byte) error {
+	l := newLoader(ini)
+	section := DefaultSection
+	for i, line := range strings.Split(string(data), "\n") {
+		n := i + 1
+		s := &tomlScanner{s: strings.TrimSuffix(line, "\r")}
+		s.skipSpace()
+		if s.done() || s.peek() == '#' {
+			continue
+		}
+
+		if s.peek() == '[' {
+			if strings.HasPrefix(s.rest(), "[[") {
+				return tomlError(n, errors.New("arrays of tables are not supported"))
+			}
+			s.i++
+			path, err := s.keys()
+			if err == nil && !s.consume(']') {
+				err = errors.New("missing ]")
+			}
+			if err == nil {
+				err = s.end()
+			}
+			if err != nil {
+				return tomlError(n, err)
+			}
+			section = ini.joinSection(path)
+			l.section(section, n)
+			continue
+		}
+
+		path, err := s.keys()
+		if err == nil && !s.consume('=') {
+			err = errors.New("missing =")
+		}
+		var value string
+		if err == nil {
+			value, err = s.value(false)
+		}
+		if err == nil {
+			err = s.end()
+		}
+		if err != nil {
+			return tomlError(n, err)
+		}
+		target := section
+		if len(path) > 1 {
+			target = ini.joinSection(append(ini.sectionPath(section), path[:len(path)-1]...))
+		}
+		l.set(target, path[len(path)-1], value, n)
+	}
+	return nil
+}
+
+func tomlError(line int, err error) error {
+	return errors.New("Bad TOML at line " + strconv.Itoa(line) + " : " + err.Error())
+}
+
+// tomlScanner scans one line of TOML
+type tomlScanner struct {
+	s string
+	i int
+}
+
+func (s *tomlScanner) done() bool   { return s.i >= len(s.s) }
+func (s *tomlScanner) rest() string { return s.s[s.i:] }
+
+func (s *tomlScanner) peek() byte {
+	if s.done() {
+		return 0
+	}
+	return s.s[s.i]
+}
+
+func (s *tomlScanner) skipSpace() {
+	for !s.done() && isSpace(s.s[s.i]) {
+		s.i++
+	}
+}
+
+// consume skips the whitespace and c, and reports whether c is found
+func (s *tomlScanner) consume(c byte) bool {
+	s.skipSpace()
+	if s.peek() != c {
+		return false
+	}
+	s.i++
+	return true
+}
+
+// end checks that only whitespace and a comment are left
+func (s *tomlScanner) end() error {
+	s.skipSpace()
+	if !s.done() && s.peek() != '#' {
+		return errors.New("unexpected " + strconv.Quote(s.rest()))
+	}
+	return nil
+}
+
+// keys scans a dotted key
+func (s *tomlScanner) keys() ([]string, error) {
+	var path []string
+	for {
+		s.skipSpace()
+		key, err := s.key()
+		if err != nil {
+			return nil, err
+		}
+		path = append(path, key)
+		if !s.consume('.') {
+			return path, nil
+		}
+	}
+}
+
+// key scans a bare or quoted key
+func (s *tomlScanner) key() (string, error) {
+	switch s.peek() {
+	case '"', '\'':
+		return s.quoted()
+	}
+	start := s.i
+	for !s.done() && isBareKey(s.s[s.i]) {
+		s.i++
+	}
+	if s.i == start {
+		return "", errors.New("missing key")
+	}
+	return s.s[start:s.i], nil
+}
+
+// quoted scans a basic or literal string
+func (s *tomlScanner) quoted() (string, error) {
+	rest := s.rest()
+	if strings.HasPrefix(rest, `"""`) || strings.HasPrefix(rest, "'''") {
+		return "", errors.New("multi-line strings are not supported")
+	}
+	if rest[0] == '\'' {
+		end := strings.IndexByte(rest[1:], '\'')
+		if end < 0 {
+			return "", errors.New("unterminated string")
+		}
+		s.i += end + 2
+		return rest[1 : end+1], nil
+	}
+	n := quotedLen([]byte(rest), `"`)
+	if n < 0 {
+		return "", errors.New("unterminated string")
+	}
+	s.i += n
+	return unescapeBasic(rest[1 : n-1])
+}
+
+// value scans a value, which is a scalar if inArray is true
+func (s *tomlScanner) value(inArray bool) (string, error) {
+	s.skipSpace()
+	switch s.peek() {
+	case '"', '\'':
+		return s.quoted()
+	case '{':
+		return "", errors.New("inline tables are not supported")
+	case '[':
+		if inArray {
+			return "", errors.New("nested arrays are not supported")
+		}
+		s.i++
+		var items []string
+		for !s.consume(']') {
+			if s.done() {
+				return "", errors.New("multi-line arrays are not supported")
+			}
+			item, err := s.value(true)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, item)
+			if !s.consume(',') && !strings.HasPrefix(strings.TrimLeft(s.rest(), " \t"), "]") {
+				return "", errors.New("missing , or ]")
+			}
+		}
+		return strings.Join(items, ","), nil
+	}
+
+	// A number, a boolean or a date ends at a comment, or at the end of
+	// the item of an array
+	end := len(s.s)
+	if i := strings.IndexAny(s.rest(), tomlStops(inArray)); i >= 0 {
+		end = s.i + i
+	}
+	token := strings.TrimSpace(s.s[s.i:end])
+	s.i = end
+	if token == "" {
+		return "", errors.New("missing value")
+	}
+	return tomlBareValue(token)
+}
+
+// tomlBareValue returns the value of a boolean, a number or a date, and an
+// error if token is none of them. The integers are returned in decimal
+// without underscores, and the other values as they are written.
+func tomlBareValue(token string) (string, error) {
+	switch strings.TrimLeft(token, "+-") {
+	case "true", "false":
+		if token[0] != '+' && token[0] != '-' {
+			return token, nil
+		}
+	case "inf", "nan":
+		if len(token) <= 4 {
+			return token, nil
+		}
+	}
+
+	isDigit := func(c byte) bool { return '0' <= c && c <= '9' }
+	if len(token) > 2 && token[0] == '0' && strings.IndexByte("xob", token[1]) >= 0 {
+		isDigit = func(c byte) bool { return strings.IndexByte("0123456789abcdefABCDEF", c) >= 0 }
+	}
+	// An underscore must be between two digits
+	for i := 0; i < len(token); i++ {
+		if token[i] == '_' && (i == 0 || i == len(token)-1 || !isDigit(token[i-1]) || !isDigit(token[i+1])) {
+			return "", errors.New("bad value " + token)
+		}
+	}
+	plain := strings.ReplaceAll(token, "_", "")
+	if len(plain) > 2 && plain[0] == '0' && strings.IndexByte("xob", plain[1]) >= 0 {
+		if i, err := strconv.ParseInt(plain, 0, 64); err == nil {
+			return strconv.FormatInt(i, 10), nil
+		}
+	} else if isTOMLNumber(plain) {
+		return plain, nil
+	} else if isTOMLDate(token) {
+		return token, nil
+	}
+	return "", errors.New("bad value " + token)
+}
+
+// isTOMLDate reports whether s is an offset date-time, a local date-time,
+// a local date or a local time of TOML
+func isTOMLDate(s string) bool {
+	if len(s) > 10 && (s[10] == ' ' || s[10] == 't') {
+		s = s[:10] + "T" + s[11:]
+	}
+	if strings.HasSuffix(s, "z") {
+		s = s[:len(s)-1] + "Z"
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05Z07:00", "2006-01-02T15:04:05", "2006-01-02", "15:04:05"} {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// isTOMLNumber reports whether s is a number which TOML can hold, i.e. an
+// integer which fits in 64 bits or a float which fits in a float64. YAML
+// parsers read such numbers back the same as well.
+func isTOMLNumber(s string) bool {
+	if !isNumber(s) {
+		return false
+	}
+	var err error
+	if strings.ContainsAny(s, ".eE") {
+		_, err = strconv.ParseFloat(s, 64)
+	} else {
+		_, err = strconv.ParseInt(s, 10, 64)
+	}
+	return err == nil
+}
+
+func tomlStops(inArray bool) string {
+	if inArray {
+		return "#,]"
+	}
+	return "#"
+}
+
+func isBareKey(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_' || c == '-'
+}
+
+func writeTOMLKey(buf *bufio.Writer, key string) {
+	for i := 0; i < len(key); i++ {
+		if !isBareKey(key[i]) {
+			writeBasicString(buf, key)
+			return
+		}
+	}
+	if key == "" {
+		writeBasicString(buf, key)
+		return
+	}
+	buf.WriteString(key)
+}
+
+// writeBasicString writes s as a double quoted string of TOML or YAML, in
+// which the quotation marks, the backslashes and the control characters
+// are escaped
+func writeBasicString(buf *bufio.Writer, s string) {
+	const hex = "0123456789abcdef"
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			buf.WriteByte('\\')
+			buf.WriteRune(r)
+		case r == '\n':
+			buf.WriteString(`\n`)
+		case r == '\t':
+			buf.WriteString(`\t`)
+		case r == '\r':
+			buf.WriteString(`\r`)
+		case r < ' ' || r == 0x7f:
+			buf.WriteString(`\u00`)
+			buf.WriteByte(hex[r>>4])
+			buf.WriteByte(hex[r&0xf])
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// unescapeBasic replaces the escape sequences of a double quoted string of
+// TOML or YAML
+func unescapeBasic(s string) (string, error) {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		if i == len(s) {
+			return "", errors.New("bad escape sequence at the end")
+		}
+		size := 0
+		switch c := s[i]; c {
+		case 'b':
+			b.WriteByte('\b')
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'f':
+			b.WriteByte('\f')
+		case 'r':
+			b.WriteByte('\r')
+		case 'a':
+			b.WriteByte('\a')
+		case 'v':
+			b.WriteByte('\v')
+		case 'e':
+			b.WriteByte(0x1b)
+		case '0':
+			b.WriteByte(0)
+		case '"', '\\', '/', ' ':
+			b.WriteByte(c)
+		case 'x':
+			size = 2
+		case 'u':
+			size = 4
+		case 'U':
+			size = 8
+		default:
+			return "", errors.New("bad escape sequence \\" + string(c))
+		}
+		if size == 0 {
+			continue
+		}
+		if i+size >= len(s) {
+			return "", errors.New("bad escape sequence \\" + s[i:])
+		}
+		r, err := strconv.ParseUint(s[i+1:i+1+size], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(r)) {
+			return "", errors.New("bad escape sequence \\" + s[i:i+1+size])
+		}
+		b.WriteRune(rune(r))
+		i += size
+	}
+	return b.String(), nil
+}
+
+// isNumber reports whether s is an integer or a float written the same in
+// TOML and YAML, without leading zeros, e.g. -1, 0.5 or 1e10
+func isNumber(s string) bool {
+	i := 0
+	if i < len(s) && (s[i] == '-' || s[i] == '+') {
+		i++
+	}
+	digits := func() int {
+		start := i
+		for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+			i++
+		}
+		return i - start
+	}
+
+	start := i
+	n := digits()
+	if n == 0 || (n > 1 && s[start] == '0') {
+		return false
+	}
+	if i < len(s) && s[i] == '.' {
+		i++
+		if digits() == 0 {
+			return false
+		}
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '-' || s[i] == '+') {
+			i++
+		}
+		if digits() == 0 {
+			return false
+		}
+	}
+	return i == len(s)
+}
+
+// nonEmptySections returns the stored names of the sections in document
+// order, without the default section if it is empty
+func (ini *INI) nonEmptySections() []string {
+	var sections []string
+	for _, section := range ini.sectionList() {
+		kv, ok := ini.sections[section]
+		if ok && (section != DefaultSection || len(kv) > 0) {
+			sections = append(sections, section)
+		}
+	}
+	return sections
+}
+
+// sectionPath splits the name of a section into the names of the section
+// and its ancestors, e.g. [a.b "c.d"] into a, b and c.d if the git config
+// style names are understood, see SetSubsections
+func (ini *INI) sectionPath(section string) []string {
+	if section == DefaultSection {
+		return nil
+	}
+	if name, sub, ok := splitSubsection(section); ok && ini.subsections {
+		return append(strings.Split(name, SubsectionSeparator), sub)
+	}
+	return strings.Split(section, SubsectionSeparator)
+}
+
+// joinSection returns the name of the section of the path, which is a git
+// config style name if the last name holds a dot and such names are
+// understood, see SetSubsections
+func (ini *INI) joinSection(path []string) string {
+	n := len(path)
+	if n > 1 && ini.subsections && strings.Contains(path[n-1], SubsectionSeparator) {
+		sub := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(path[n-1])
+		return strings.Join(path[:n-1], SubsectionSeparator) + ` "` + sub + `"`
+	}
+	return strings.Join(path, SubsectionSeparator)
+}
@@ -0,0 +1,232 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+const convertTestData = `product=goini
+debug=false
+[server]
+port=8080
+ratio=1.5
+version=08
+name="x<y"
+path=c:\dir
+[server.http]
+gzip=true
+[remote "a.b"]
+url=git@host
+[my app]
+`
+
+func tomlString(t *testing.T, ini *INI) string {
+	var buf bytes.Buffer
+	err := ini.WriteTOML(&buf)
+	assert.Equal(t, nil, err)
+	return buf.String()
+}
+
+func TestWriteTOML(t *testing.T) {
+	ini := parseTestINI(t, convertTestData, parseSubsections)
+	assert.Equal(t, tomlString(t, ini), `product = "goini"
+debug = false
+
+[server]
+port = 8080
+ratio = 1.5
+version = "08"
+name = "\"x<y\""
+path = "c:\\dir"
+
+[server.http]
+gzip = true
+
+[remote."a.b"]
+url = "git@host"
+
+["my app"]
+`)
+	assert.Equal(t, tomlString(t, New()), "")
+
+	ini = New()
+	ini.Set("max", "9223372036854775807")
+	ini.Set("big", "99999999999999999999")
+	ini.Set("huge", "1e999")
+	assert.Equal(t, tomlString(t, ini), "max = 9223372036854775807\nbig = \"99999999999999999999\"\nhuge = \"1e999\"\n")
+}
+
+func TestWriteTOMLCollision(t *testing.T) {
+	ini := New()
+	ini.SectionSet("server", "http", "on")
+	ini.SectionSet("server.http", "gzip", "true")
+	var buf bytes.Buffer
+	err := ini.WriteTOML(&buf)
+	assert.NotEqual(t, nil, err)
+
+	ini = New()
+	ini.Set("server", "x")
+	ini.SectionSet("server", "port", "1")
+	err = ini.WriteTOML(&buf)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestParseTOML(t *testing.T) {
+	ini := New()
+	ini.Set("stale", "1")
+	err := ini.ParseTOML([]byte(`# comment
+title = "TOML \"example\"\t\u00e9" # inline
+literal = 'c:\dir'
+count = 1_000
+hex = 0xff
+neg = -inf
+ratio = 6.02e+23
+enabled = true
+date = 1979-05-27T07:32:00Z
+hosts = [ "a", 'b', 3, ]
+server.port = 80
+
+[ database ]
+ports = [8000, 8001]
+"key with space" = "x"
+
+[remote."a.b"]
+url = "git@host"
+`))
+	assert.Equal(t, nil, err)
+	tests := []struct {
+		section, key, value string
+		line                int
+	}{
+		{"", "title", "TOML \"example\"\té", 2},
+		{"", "literal", `c:\dir`, 3},
+		{"", "count", "1000", 4},
+		{"", "hex", "255", 5},
+		{"", "neg", "-inf", 6},
+		{"", "ratio", "6.02e+23", 7},
+		{"", "enabled", "true", 8},
+		{"", "date", "1979-05-27T07:32:00Z", 9},
+		{"", "hosts", "a,b,3", 10},
+		{"server", "port", "80", 11},
+		{"database", "ports", "8000,8001", 14},
+		{"database", "key with space", "x", 15},
+		{"remote.a.b", "url", "git@host", 18},
+	}
+	for _, test := range tests {
+		v, ok := ini.SectionGet(test.section, test.key)
+		assert.Equal(t, v, test.value, test.key)
+		assert.Equal(t, ok, true, test.key)
+		assert.Equal(t, ini.Line(test.section, test.key), test.line, test.key)
+	}
+	_, ok := ini.Get("stale")
+	assert.Equal(t, ok, false)
+	assert.Equal(t, ini.sectionList(), []string{"", "server", "database", "remote.a.b"})
+}
+
+func TestTOMLRoundTrip(t *testing.T) {
+	for _, subsections := range []bool{false, true} {
+		ini := parseTestINI(t, convertTestData, func(ini *INI) {
+			ini.SetParseSection(true)
+			ini.SetSubsections(subsections)
+		})
+		c := New()
+		c.SetSubsections(subsections)
+		err := c.ParseTOML([]byte(tomlString(t, ini)))
+		assert.Equal(t, nil, err)
+		assert.Equal(t, c.Equal(ini), true)
+		assert.Equal(t, c.spellSection(c.sectionName(`remote "a.b"`)), `remote "a.b"`)
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	tests := []string{
+		"[[products]]",
+		"[table",
+		"a = {b = 1}",
+		`a = """multi"""`,
+		"a = [1,",
+		"a = [[1]]",
+		`a = "x`,
+		`a = "\q"`,
+		"a = ",
+		"a 1",
+		"= 1",
+		`a = "x" y`,
+		"a = 010",
+		"a = 1__0",
+		"a = _1",
+		"a = 1_",
+		"a = 0x_ff",
+		"a = -0xff",
+		"a = 99999999999999999999999",
+		"a = 1.",
+		"a = .5",
+		"a = +true",
+		"a = yes",
+		"a = 1979-13-27",
+		"a = 25:00:00",
+	}
+	for _, test := range tests {
+		err := New().ParseTOML([]byte(test))
+		assert.NotEqual(t, nil, err, test)
+	}
+}
+
+func TestUnescapeBasic(t *testing.T) {
+	tests := []struct {
+		s, want string
+	}{
+		{`plain`, "plain"},
+		{`\b\t\n\f\r\"\\\/`, "\b\t\n\f\r\"\\/"},
+		{`\u00e9\U0001F600\x41`, "é😀A"},
+	}
+	for _, test := range tests {
+		s, err := unescapeBasic(test.s)
+		assert.Equal(t, nil, err, test.s)
+		assert.Equal(t, s, test.want, test.s)
+	}
+	for _, s := range []string{`\`, `\u12`, `\uzzzz`, `\UFFFFFFFF`, `\k`} {
+		_, err := unescapeBasic(s)
+		assert.NotEqual(t, nil, err, s)
+	}
+}
+
+func TestIsNumber(t *testing.T) {
+	for _, s := range []string{"0", "-1", "+1", "1.5", "1e10", "-1.5E-3"} {
+		assert.Equal(t, isNumber(s), true, s)
+	}
+	for _, s := range []string{"", "08", "1.", ".5", "1e", "0x1f", "1_000", "inf", "1.5.1"} {
+		assert.Equal(t, isNumber(s), false, s)
+	}
+}
+
+func TestTOMLBareValue(t *testing.T) {
+	tests := []struct {
+		token, want string
+	}{
+		{"false", "false"},
+		{"+inf", "+inf"},
+		{"nan", "nan"},
+		{"-1_000", "-1000"},
+		{"0xdead_beef", "3735928559"},
+		{"0o755", "493"},
+		{"0b1010", "10"},
+		{"5e+2_2", "5e+22"},
+		{"1979-05-27 07:32:00.999-07:00", "1979-05-27 07:32:00.999-07:00"},
+		{"1979-05-27t07:32:00z", "1979-05-27t07:32:00z"},
+		{"1979-05-27T07:32:00", "1979-05-27T07:32:00"},
+		{"1979-05-27", "1979-05-27"},
+		{"07:32:00.5", "07:32:00.5"},
+	}
+	for _, test := range tests {
+		v, err := tomlBareValue(test.token)
+		assert.Equal(t, nil, err, test.token)
+		assert.Equal(t, v, test.want, test.token)
+	}
+}
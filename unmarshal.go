@@ -0,0 +1,280 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// tagPatterns caches the compiled patterns of the regex rules of the tags
+var tagPatterns sync.Map
+
+// Unmarshal stores the values of this INI in the struct pointed to by v.
+//
+// The fields of v are read from the default section. A field of struct type
+// is read from the section with the name of the field, and a field of struct
+// type nested in it from the subsection with the name of the field, e.g. :
+//
+//	type Config struct {
+//		Product string `ini:"product"`
+//		Server  struct {
+//			Port int    `ini:"port" validate:"required,min=1,max=65535"`
+//			Mode string `ini:"mode" validate:"oneof=debug release"`
+//			TLS  struct {
+//				Cert string `ini:"cert"`
+//			} `ini:"tls"`
+//		} `ini:"server"`
+//	}
+//
+// reads the port from the section [server] and the cert from the section
+// [server.tls], which may also be written [server "tls"].
+//
+// The ini tag gives the name of the key or section, which is the field name
+// if the tag is missing. A field with the tag "-" is skipped, and so is a
+// field whose key does not exist. The supported field types are string,
+// bool, the integer and float types, time.Duration and []string (a comma
+// separated list).
+//
+// The validate tag is a comma separated list of rules checked before the
+// value is stored:
+//
+//	required      the key must exist
+//	min=N, max=N  the inclusive range of a number or duration
+//	oneof=A B C   the value must be one of the space separated values
+//	regex=RE      the whole value must match RE; it must be the last rule
+//	              as RE may contain commas
+//
+// If any value is invalid, Unmarshal returns a ValidationError holding a
+// Violation for every invalid key, reported by section, key and line.
+func (ini *INI) Unmarshal(v interface{}) error {
+	return ini.unmarshal(v, false)
+}
+
+// UnmarshalStrict is like Unmarshal, but also reports every section and
+// key of this INI which is not consumed by a field of v as a Violation,
+// suggesting the most similar field name. See Schema.Strict.
+func (ini *INI) UnmarshalStrict(v interface{}) error {
+	return ini.unmarshal(v, true)
+}
+
+type decoder struct {
+	ini        *INI
+	violations ValidationError
+	known      knownKeys
+}
+
+func (ini *INI) unmarshal(v interface{}, strict bool) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("Unmarshal needs a non-nil pointer to a struct")
+	}
+
+	d := &decoder{ini: ini, known: make(knownKeys)}
+	err := d.unmarshalSection(DefaultSection, rv.Elem())
+	if err != nil {
+		return err
+	}
+	if strict {
+		d.violations = append(d.violations, d.known.unknown(ini)...)
+	}
+	if len(d.violations) > 0 {
+		return d.violations
+	}
+	return nil
+}
+
+// unmarshalSection stores the keys of section in the struct sv. Fields of
+// struct type are read from the subsections of section.
+func (d *decoder) unmarshalSection(section string, sv reflect.Value) error {
+	ini := d.ini
+	d.known.add(section, "")
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		sf := st.Field(i)
+		if sf.PkgPath != "" && !(sf.Anonymous && sf.Type.Kind() == reflect.Struct) {
+			continue // unexported
+		}
+		name := sf.Tag.Get("ini")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		fv := sv.Field(i)
+		if sf.Type.Kind() == reflect.Struct && sf.Type != durationType {
+			if sf.Anonymous {
+				// The fields of an embedded struct belong to the same section
+				if err := d.unmarshalSection(section, fv); err != nil {
+					return err
+				}
+				continue
+			}
+			sub := name
+			if section != DefaultSection {
+				sub = section + SubsectionSeparator + name
+			}
+			if err := d.unmarshalSection(sub, fv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		ks, err := fieldKeySchema(name, sf)
+		if err != nil {
+			return err
+		}
+		d.known.add(section, name)
+
+		value, ok := ini.SectionGet(section, name)
+		if !ok {
+			if ks.Required {
+				d.violations = append(d.violations, Violation{
+					Section: section,
+					Key:     name,
+					Line:    ini.SectionLine(section),
+					Message: "is required"})
+			}
+			continue
+		}
+
+		err = ks.check(value, &tagPatterns)
+		if err == nil {
+			err = setField(fv, value)
+		}
+		if err != nil {
+			d.violations = append(d.violations, Violation{
+				Section: section,
+				Key:     name,
+				Line:    ini.Line(section, name),
+				Message: err.Error()})
+		}
+	}
+	return nil
+}
+
+// fieldKeySchema builds the KeySchema checking the value of a field from
+// the type and the validate tag of the field
+func fieldKeySchema(name string, sf reflect.StructField) (*KeySchema, error) {
+	ks := &KeySchema{Name: name, Type: TypeString}
+	switch sf.Type.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		ks.Type = TypeInt
+		if sf.Type == durationType {
+			ks.Type = TypeDuration
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		ks.Type = TypeInt
+	case reflect.Float32, reflect.Float64:
+		ks.Type = TypeFloat
+	case reflect.Bool:
+		ks.Type = TypeBool
+	case reflect.String:
+	case reflect.Slice:
+		if sf.Type.Elem().Kind() != reflect.String {
+			return nil, errors.New("Unsupported type " + sf.Type.String() + " of field " + sf.Name)
+		}
+	default:
+		return nil, errors.New("Unsupported type " + sf.Type.String() + " of field " + sf.Name)
+	}
+
+	tag := sf.Tag.Get("validate")
+	for tag != "" {
+		var rule string
+		if strings.HasPrefix(tag, "regex=") {
+			rule, tag = tag, ""
+		} else if pos := strings.IndexByte(tag, ','); pos >= 0 {
+			rule, tag = tag[:pos], tag[pos+1:]
+		} else {
+			rule, tag = tag, ""
+		}
+
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "required":
+			ks.Required = true
+		case "min", "max":
+			if ks.Type != TypeInt && ks.Type != TypeFloat && ks.Type != TypeDuration {
+				return nil, errors.New("Rule " + name + " needs a number or duration field but " + sf.Name + " is " + sf.Type.String())
+			}
+			if name == "min" {
+				ks.Min = arg
+			} else {
+				ks.Max = arg
+			}
+		case "oneof":
+			ks.Enum = strings.Fields(arg)
+		case "regex":
+			ks.Pattern = arg
+		case "":
+		default:
+			return nil, errors.New("Unknown validate rule " + strconv.Quote(rule) + " of field " + sf.Name)
+		}
+	}
+	return ks, nil
+}
+
+// setField converts value to the type of fv and stores it
+func setField(fv reflect.Value, value string) error {
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Bool:
+		b, _ := parseBool(value)
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		var err error
+		if fv.Type() == durationType {
+			var d time.Duration
+			d, err = time.ParseDuration(value)
+			i = int64(d)
+		} else {
+			i, err = strconv.ParseInt(value, 10, fv.Type().Bits())
+		}
+		if err != nil {
+			return errors.New("is out of range of " + fv.Type().String() + " : " + value)
+		}
+		fv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, fv.Type().Bits())
+		if err != nil {
+			return errors.New("is out of range of " + fv.Type().String() + " : " + value)
+		}
+		fv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, fv.Type().Bits())
+		if err != nil {
+			return errors.New("is out of range of " + fv.Type().String() + " : " + value)
+		}
+		fv.SetFloat(f)
+	case reflect.Slice:
+		var list []string
+		for _, s := range strings.Split(value, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				list = append(list, s)
+			}
+		}
+		// The elements are set one by one, as the slice and its elements
+		// may be of named types like []MyString
+		slice := reflect.Zero(fv.Type())
+		if len(list) > 0 {
+			slice = reflect.MakeSlice(fv.Type(), len(list), len(list))
+			for i, s := range list {
+				slice.Index(i).SetString(s)
+			}
+		}
+		fv.Set(slice)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+)
+
+type testCommon struct {
+	Debug bool `ini:"debug"`
+}
+
+type testConfig struct {
+	testCommon
+	Mid     string  `ini:"mid"`
+	Product string  `ini:"product" validate:"required,oneof=ppp test"`
+	Version float64 `ini:"version" validate:"min=1"`
+	Ignored string  `ini:"-"`
+	Missing int     `ini:"missing"`
+	Sss     struct {
+		Aa     string   `ini:"aa" validate:"regex=b+"`
+		Appext []string `ini:"appext"`
+	} `ini:"sss"`
+	Ddd struct {
+		Age     uint8         `ini:"age" validate:"max=150"`
+		Height  float32       `ini:"height"`
+		Debug   bool          `ini:"debug"`
+		Timeout time.Duration `ini:"timeout"`
+	} `ini:"ddd"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	ini := New()
+	err := ini.ParseFile(filepath.Join(getTestDataDir(t), "ini_parser_testfile.ini"))
+	assert.Equal(t, nil, err)
+	ini.SectionSet("ddd", "timeout", "1m")
+
+	cfg := testConfig{Missing: 3, Ignored: "x"}
+	err = ini.Unmarshal(&cfg)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, cfg.Debug, false)
+	assert.Equal(t, cfg.Mid, "ac9219aa5232c4e519ae5fcb4d77ae5b")
+	assert.Equal(t, cfg.Product, "ppp")
+	assert.Equal(t, cfg.Version, 4.4)
+	assert.Equal(t, cfg.Ignored, "x")
+	assert.Equal(t, cfg.Missing, 3)
+	assert.Equal(t, cfg.Sss.Aa, "bb")
+	assert.Equal(t, cfg.Sss.Appext, []string{"ab=cd"})
+	assert.Equal(t, cfg.Ddd.Age, uint8(30))
+	assert.Equal(t, cfg.Ddd.Height, float32(175.6))
+	assert.Equal(t, cfg.Ddd.Debug, true)
+	assert.Equal(t, cfg.Ddd.Timeout, time.Minute)
+}
+
+func TestUnmarshalValidate(t *testing.T) {
+	ini := New()
+	err := ini.ParseFile(filepath.Join(getTestDataDir(t), "ini_parser_testfile.ini"))
+	assert.Equal(t, nil, err)
+	ini.Delete("", "product")
+	ini.Set("version", "0.5")
+	ini.SectionSet("sss", "aa", "cc")
+	ini.SectionSetInt("ddd", "age", 300)
+	ini.SectionSet("ddd", "height", "tall")
+
+	var cfg testConfig
+	err = ini.Unmarshal(&cfg)
+	assert.NotEqual(t, nil, err)
+	violations := err.(ValidationError)
+	assert.Equal(t, len(violations), 5)
+	assert.Equal(t, violations[0], Violation{"", "product", 0, "is required"})
+	assert.Equal(t, violations[1], Violation{"", "version", 5, "must be >= 1 but is 0.5"})
+	assert.Equal(t, violations[2], Violation{"sss", "aa", 18, `must match b+ but is "cc"`})
+	assert.Equal(t, violations[3], Violation{"ddd", "age", 21, "must be <= 150 but is 300"})
+	assert.Equal(t, violations[4], Violation{"ddd", "height", 22, `must be a number but is "tall"`})
+
+	ini.SectionSetInt("ddd", "age", 100)
+	ini.SectionSetInt("ddd", "age", -1)
+	err = ini.Unmarshal(&cfg)
+	violations = err.(ValidationError)
+	assert.Equal(t, violations[3], Violation{"ddd", "age", 21, "is out of range of uint8 : -1"})
+}
+
+func TestUnmarshalNamedTypes(t *testing.T) {
+	type tag string
+	type tags []tag
+	ini := New()
+	ini.Set("name", "goini")
+	ini.Set("tags", "a, b")
+	ini.Set("none", "")
+
+	var cfg struct {
+		Name tag  `ini:"name"`
+		Tags tags `ini:"tags"`
+		None tags `ini:"none"`
+	}
+	err := ini.Unmarshal(&cfg)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, cfg.Name, tag("goini"))
+	assert.Equal(t, cfg.Tags, tags{"a", "b"})
+	assert.Equal(t, cfg.None == nil, true)
+}
+
+func TestUnmarshalError(t *testing.T) {
+	ini := New()
+	ini.Set("a", "1")
+
+	var cfg testConfig
+	assert.NotEqual(t, nil, ini.Unmarshal(cfg))
+	assert.NotEqual(t, nil, ini.Unmarshal((*testConfig)(nil)))
+
+	var badRule struct {
+		A string `ini:"a" validate:"min=1"`
+	}
+	assert.NotEqual(t, nil, ini.Unmarshal(&badRule))
+
+	var unknownRule struct {
+		A int `ini:"a" validate:"positive"`
+	}
+	assert.NotEqual(t, nil, ini.Unmarshal(&unknownRule))
+
+	var badType struct {
+		A []int `ini:"a"`
+	}
+	assert.NotEqual(t, nil, ini.Unmarshal(&badType))
+
+	var badMapType struct {
+		A map[string]string `ini:"a"`
+	}
+	assert.NotEqual(t, nil, ini.Unmarshal(&badMapType))
+}
@@ -0,0 +1,125 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+// Section is a view of one section of an INI, returned by INI.Section.
+// It holds no data, so it reflects the later changes of the INI, and the
+// section does not need to exist. e.g. :
+//
+//	server := ini.Section("server")
+//	port, _ := server.GetInt("port")
+//	server.Set("host", "localhost")
+type Section struct {
+	ini  *INI
+	name string
+}
+
+// Section returns the view of the section named name
+func (ini *INI) Section(name string) *Section {
+	return &Section{ini: ini, name: name}
+}
+
+// Name returns the name of the section
+func (s *Section) Name() string {
+	return s.name
+}
+
+// INI returns the INI the section belongs to
+func (s *Section) INI() *INI {
+	return s.ini
+}
+
+// Exists reports whether the section exists
+func (s *Section) Exists() bool {
+	return s.ini.HasSection(s.name)
+}
+
+// Sub returns the view of the subsection name of this section, e.g. the
+// subsection "http" of the section "server" is the section "server.http".
+// A subsection of the default section is a top level section.
+func (s *Section) Sub(name string) *Section {
+	if s.name == DefaultSection {
+		return s.ini.Section(name)
+	}
+	return s.ini.Section(s.name + SubsectionSeparator + name)
+}
+
+// Get looks up a value for a key in the section. See INI.SectionGet.
+func (s *Section) Get(key string) (string, bool) {
+	return s.ini.SectionGet(s.name, key)
+}
+
+// GetInt gets value as int. See INI.SectionGetInt.
+func (s *Section) GetInt(key string) (int, bool) {
+	return s.ini.SectionGetInt(s.name, key)
+}
+
+// GetFloat gets value as float64. See INI.SectionGetFloat.
+func (s *Section) GetFloat(key string) (float64, bool) {
+	return s.ini.SectionGetFloat(s.name, key)
+}
+
+// GetBool gets value as bool. See INI.SectionGetBool.
+func (s *Section) GetBool(key string) (bool, bool) {
+	return s.ini.SectionGetBool(s.name, key)
+}
+
+// Has reports whether the key exists in the section
+func (s *Section) Has(key string) bool {
+	return s.ini.HasKey(s.name, key)
+}
+
+// Set stores the key/value pair in the section, creating the section if
+// it does not exist. See INI.SectionSet.
+func (s *Section) Set(key, value string) {
+	s.ini.SectionSet(s.name, key, value)
+}
+
+// SetInt stores the key and the int value in the section
+func (s *Section) SetInt(key string, value int) {
+	s.ini.SectionSetInt(s.name, key, value)
+}
+
+// SetFloat stores the key and the float64 value in the section
+func (s *Section) SetFloat(key string, value float64) {
+	s.ini.SectionSetFloat(s.name, key, value)
+}
+
+// SetBool stores the key and the bool value in the section
+func (s *Section) SetBool(key string, value bool) {
+	s.ini.SectionSetBool(s.name, key, value)
+}
+
+// Delete deletes the key in the section
+func (s *Section) Delete(key string) {
+	s.ini.Delete(s.name, key)
+}
+
+// Keys returns the keys of the section in document order
+func (s *Section) Keys() []string {
+	var keys []string
+	s.ini.Pairs(s.name)(func(key, value string) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// Pairs returns an iterator over the key/value pairs of the section.
+// See INI.Pairs.
+func (s *Section) Pairs() func(yield func(key, value string) bool) {
+	return s.ini.Pairs(s.name)
+}
+
+// Line returns the line number of the key in the section. See INI.Line.
+func (s *Section) Line(key string) int {
+	return s.ini.Line(s.name, key)
+}
+
+// Subscribe registers fn to be called every time any key in the section is
+// changed. See INI.SubscribeSection.
+func (s *Section) Subscribe(fn SectionChangeFunc) (cancel func()) {
+	return s.ini.SubscribeSection(s.name, fn)
+}
@@ -0,0 +1,84 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestSectionView(t *testing.T) {
+	ini := parseTestINI(t, sectionTestData, parseSections)
+	s := ini.Section("sss")
+	assert.Equal(t, s.Name(), "sss")
+	assert.Equal(t, s.INI(), ini)
+	assert.Equal(t, s.Exists(), true)
+
+	v, ok := s.Get("c")
+	assert.Equal(t, v, "3")
+	assert.Equal(t, ok, true)
+	i, ok := s.GetInt("b")
+	assert.Equal(t, i, 4)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, s.Has("a"), false)
+	assert.Equal(t, s.Keys(), []string{"c", "b"})
+	assert.Equal(t, s.Line("b"), 5)
+
+	s.Set("a", "x")
+	s.SetInt("n", 5)
+	s.SetFloat("f", 1.5)
+	s.SetBool("ok", true)
+	s.Delete("c")
+	assert.Equal(t, s.Keys(), []string{"b", "a", "n", "f", "ok"})
+	f, _ := s.GetFloat("f")
+	assert.Equal(t, f, 1.5)
+	b, _ := s.GetBool("ok")
+	assert.Equal(t, b, true)
+	v, _ = ini.SectionGet("sss", "a")
+	assert.Equal(t, v, "x")
+}
+
+func TestSectionViewReflectsChanges(t *testing.T) {
+	ini := New()
+	s := ini.Section("later")
+	assert.Equal(t, s.Exists(), false)
+	assert.Equal(t, len(s.Keys()), 0)
+
+	ini.SectionSet("later", "k", "v")
+	assert.Equal(t, s.Exists(), true)
+	v, _ := s.Get("k")
+	assert.Equal(t, v, "v")
+
+	var changes []string
+	cancel := s.Subscribe(func(key, old, new string, deleted bool) {
+		changes = append(changes, key+":"+old+"->"+new)
+	})
+	defer cancel()
+	s.Set("k", "w")
+	assert.Equal(t, changes, []string{"k:v->w"})
+
+	var pairs []string
+	s.Pairs()(func(key, value string) bool {
+		pairs = append(pairs, key+"="+value)
+		return true
+	})
+	assert.Equal(t, pairs, []string{"k=w"})
+
+	ini.DeleteSection("later")
+	assert.Equal(t, s.Exists(), false)
+}
+
+func TestSectionSub(t *testing.T) {
+	ini := New()
+	ini.SectionSet("server.http", "port", "80")
+
+	http := ini.Section("server").Sub("http")
+	assert.Equal(t, http.Name(), "server.http")
+	port, _ := http.GetInt("port")
+	assert.Equal(t, port, 80)
+	assert.Equal(t, ini.Section("").Sub("server").Name(), "server")
+	assert.Equal(t, http.Sub("tls").Name(), "server.http.tls")
+}
@@ -0,0 +1,332 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// WriteYAML writes this INI as a flat YAML mapping. The keys of the default
+// section come first, and every other section is a mapping of its keys,
+// e.g. server.http: followed by the indented port: 8080. The values which
+// are integers, floats, true or false are written plain, and the other
+// values as strings, which are quoted if YAML would read them otherwise.
+// It fails if a key of the default section is named like a section.
+func (ini *INI) WriteYAML(w io.Writer) error {
+	sections := ini.nonEmptySections()
+	names := make(map[string]bool)
+	for _, section := range sections {
+		names[ini.spellSection(section)] = true
+	}
+
+	buf := bufio.NewWriter(w)
+	var err error
+	for _, section := range sections {
+		indent := ""
+		if section != DefaultSection {
+			writeYAMLScalar(buf, ini.spellSection(section), true)
+			if len(ini.sections[section]) == 0 {
+				buf.WriteString(": {}\n")
+				continue
+			}
+			buf.WriteString(":\n")
+			indent = "  "
+		}
+		ini.pairs(section, func(key, value string) bool {
+			if section == DefaultSection && names[key] {
+				err = errors.New("Key " + key + " of the default section collides with section [" + key + "]")
+				return false
+			}
+			buf.WriteString(indent)
+			writeYAMLScalar(buf, key, true)
+			buf.WriteString(": ")
+			writeYAMLScalar(buf, value, false)
+			buf.WriteByte('\n')
+			return true
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return buf.Flush()
+}
+
+// ParseYAML parses the flat subset of YAML written by WriteYAML to store the
+// data in the INI. As Parse does, it replaces the sections it holds.
+//
+// The scalars of the top level mapping are the keys of the default section,
+// and a nested mapping is a section, named by the dotted path of its keys,
+// e.g. the mapping tls in the mapping server is the section [server.tls].
+// The quoted scalars are unquoted, null is stored as an empty value, and
+// the sequences of scalars are stored as comma separated lists. The block
+// scalars, the flow mappings, the multi-line scalars, the anchors, the
+// aliases and the tags are not supported.
+func (ini *INI) ParseYAML(data []byte) error {
+	type level struct {
+		indent int    // The indentation of the key of the mapping
+		child  int    // The indentation of its keys, or -1 before the first one
+		name   string // The key of the mapping
+	}
+	// pending is the key without a value on its line, which holds either a
+	// mapping, a sequence or null
+	type pending struct {
+		path   []string
+		key    string
+		indent int
+		line   int
+		items  []string
+	}
+
+	l := newLoader(ini)
+	levels := []level{{indent: -1, child: -1}}
+	var p *pending
+	path := func() []string {
+		names := make([]string, 0, len(levels)-1)
+		for _, lv := range levels[1:] {
+			names = append(names, lv.name)
+		}
+		return names
+	}
+	flush := func() {
+		if p != nil {
+			l.set(ini.joinSection(p.path), p.key, strings.Join(p.items, ","), p.line)
+			p = nil
+		}
+	}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		n := i + 1
+		line = strings.TrimSuffix(line, "\r")
+		content := strings.TrimLeft(line, " ")
+		indent := len(line) - len(content)
+		if strings.TrimSpace(content) == "" || content[0] == '#' {
+			continue
+		}
+		if content[0] == '\t' {
+			return yamlError(n, errors.New("tabs are not allowed in the indentation"))
+		}
+		if indent == 0 && (content == "---" || content == "..." || strings.HasPrefix(content, "--- ") || content[0] == '%') {
+			continue
+		}
+
+		if content == "-" || strings.HasPrefix(content, "- ") {
+			if p == nil || indent < p.indent {
+				return yamlError(n, errors.New("a sequence may only be the value of a key"))
+			}
+			item, err := yamlValue(strings.TrimSpace(content[1:]), true)
+			if err != nil {
+				return yamlError(n, err)
+			}
+			p.items = append(p.items, item)
+			continue
+		}
+
+		key, value, err := splitYAML(content)
+		if err != nil {
+			return yamlError(n, err)
+		}
+		if p != nil && indent > p.indent && p.items == nil {
+			levels = append(levels, level{indent: p.indent, child: -1, name: p.key})
+			p = nil
+		}
+		flush()
+		for levels[len(levels)-1].indent >= indent {
+			levels = levels[:len(levels)-1]
+		}
+		top := &levels[len(levels)-1]
+		if top.child < 0 {
+			top.child = indent
+		} else if top.child != indent {
+			return yamlError(n, errors.New("bad indentation"))
+		}
+
+		switch {
+		case value == "":
+			p = &pending{path: path(), key: key, indent: indent, line: n}
+		case value == "{}":
+			l.section(ini.joinSection(append(path(), key)), n)
+		default:
+			v, err := yamlValue(value, false)
+			if err != nil {
+				return yamlError(n, err)
+			}
+			l.set(ini.joinSection(path()), key, v, n)
+		}
+	}
+	flush()
+	return nil
+}
+
+func yamlError(line int, err error) error {
+	return errors.New("Bad YAML at line " + strconv.Itoa(line) + " : " + err.Error())
+}
+
+// splitYAML splits the line of a mapping into its unquoted key and its
+// value, which is empty if there is only a comment
+func splitYAML(line string) (string, string, error) {
+	var key, rest string
+	if line[0] == '"' || line[0] == '\'' {
+		var err error
+		key, rest, err = yamlScalar(line, "")
+		if err != nil {
+			return "", "", err
+		}
+		if !strings.HasPrefix(rest, ":") {
+			return "", "", errors.New("missing : after the key")
+		}
+		rest = rest[1:]
+	} else {
+		i := strings.Index(line, ": ")
+		if i < 0 && strings.HasSuffix(line, ":") {
+			i = len(line) - 1
+		}
+		if i < 0 {
+			return "", "", errors.New("missing : after the key")
+		}
+		key, rest = strings.TrimRight(line[:i], " "), line[i+1:]
+	}
+	if rest != "" && rest[0] != ' ' {
+		return "", "", errors.New("missing space after :")
+	}
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, "#") {
+		rest = ""
+	}
+	return key, rest, nil
+}
+
+// yamlValue returns the value of a key, or of an item of a sequence if
+// inSequence is true
+func yamlValue(s string, inSequence bool) (string, error) {
+	var value, rest string
+	var err error
+	if strings.HasPrefix(s, "[") && !inSequence {
+		var items []string
+		rest = strings.TrimLeft(s[1:], " ")
+		for !strings.HasPrefix(rest, "]") {
+			if rest == "" {
+				return "", errors.New("multi-line sequences are not supported")
+			}
+			var item string
+			item, rest, err = yamlScalar(rest, ",]")
+			if err != nil {
+				return "", err
+			}
+			items = append(items, item)
+			rest = strings.TrimLeft(rest, " ")
+			if strings.HasPrefix(rest, ",") {
+				rest = strings.TrimLeft(rest[1:], " ")
+			} else if !strings.HasPrefix(rest, "]") {
+				return "", errors.New("missing , or ]")
+			}
+		}
+		value, rest = strings.Join(items, ","), rest[1:]
+	} else if value, rest, err = yamlScalar(s, ""); err != nil {
+		return "", err
+	}
+	if rest = strings.TrimSpace(rest); rest != "" && rest[0] != '#' {
+		return "", errors.New("unexpected " + strconv.Quote(rest))
+	}
+	return value, nil
+}
+
+// yamlScalar scans the scalar at the start of s, of which a plain one ends
+// at a comment or at one of stops, and returns its value and the rest of s
+func yamlScalar(s, stops string) (string, string, error) {
+	if s == "" {
+		return "", "", nil
+	}
+	switch s[0] {
+	case '"':
+		n := quotedLen([]byte(s), `"`)
+		if n < 0 {
+			return "", "", errors.New("unterminated string")
+		}
+		v, err := unescapeBasic(s[1 : n-1])
+		return v, s[n:], err
+	case '\'':
+		var b strings.Builder
+		for i := 1; i < len(s); i++ {
+			if s[i] != '\'' {
+				b.WriteByte(s[i])
+			} else if i+1 < len(s) && s[i+1] == '\'' {
+				b.WriteByte('\'')
+				i++
+			} else {
+				return b.String(), s[i+1:], nil
+			}
+		}
+		return "", "", errors.New("unterminated string")
+	case '[', '{':
+		return "", "", errors.New("nested collections are not supported")
+	case '|', '>':
+		return "", "", errors.New("block scalars are not supported")
+	case '&', '*', '!':
+		return "", "", errors.New("anchors, aliases and tags are not supported")
+	}
+
+	end := len(s)
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(stops, s[i]) >= 0 || (s[i] == '#' && i > 0 && s[i-1] == ' ') {
+			end = i
+			break
+		}
+	}
+	v := strings.TrimSpace(s[:end])
+	if strings.Contains(v, ": ") || strings.HasSuffix(v, ":") {
+		return "", "", errors.New("unexpected mapping in " + strconv.Quote(v))
+	}
+	switch v {
+	case "~", "null", "Null", "NULL":
+		v = ""
+	}
+	return v, s[end:], nil
+}
+
+// writeYAMLScalar writes s plain if YAML reads it back as the same string,
+// or as the number or the boolean it is unless s is a key, and quoted
+// otherwise
+func writeYAMLScalar(buf *bufio.Writer, s string, key bool) {
+	if isYAMLPlain(s) && (!key || (s != "true" && s != "false" && !isNumber(s))) {
+		buf.WriteString(s)
+		return
+	}
+	writeBasicString(buf, s)
+}
+
+// isYAMLPlain reports whether s may be written as a plain scalar of YAML
+// without being read as null, as a boolean or as a number it is not, by
+// the parsers of YAML 1.1 or YAML 1.2
+func isYAMLPlain(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return false
+	}
+	if s == "true" || s == "false" || isTOMLNumber(s) {
+		return true
+	}
+	switch strings.ToLower(s) {
+	case "~", "null", "true", "false", "yes", "no", "on", "off", "y", "n":
+		return false
+	}
+	// The indicators, and the starts of the numbers which are not written
+	// the same in YAML, e.g. 010, .5 or +.inf
+	if strings.IndexByte("-?:,[]{}#&*!|>'\"%@`+.0123456789", s[0]) >= 0 {
+		return false
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return false
+	}
+	for _, r := range s {
+		if r < ' ' || r == 0x7f || r == utf8.RuneError || r == '\ufeff' {
+			return false
+		}
+	}
+	return true
+}
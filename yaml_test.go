@@ -0,0 +1,182 @@
+// Copyright 2014 zieckey. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goini
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func yamlString(t *testing.T, ini *INI) string {
+	var buf bytes.Buffer
+	err := ini.WriteYAML(&buf)
+	assert.Equal(t, nil, err)
+	return buf.String()
+}
+
+func TestWriteYAML(t *testing.T) {
+	ini := parseTestINI(t, convertTestData, parseSubsections)
+	assert.Equal(t, yamlString(t, ini), `product: goini
+debug: false
+server:
+  port: 8080
+  ratio: 1.5
+  version: "08"
+  name: "\"x<y\""
+  path: c:\dir
+server.http:
+  gzip: true
+remote "a.b":
+  url: git@host
+my app: {}
+`)
+	assert.Equal(t, yamlString(t, New()), "")
+
+	ini = New()
+	ini.Set("server", "x")
+	ini.SectionSet("server", "port", "1")
+	var buf bytes.Buffer
+	err := ini.WriteYAML(&buf)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestWriteYAMLScalar(t *testing.T) {
+	tests := []struct {
+		s, value, key string
+	}{
+		{"plain text", "plain text", "plain text"},
+		{"1.5", "1.5", `"1.5"`},
+		{"true", "true", `"true"`},
+		{"", `""`, `""`},
+		{"yes", `"yes"`, `"yes"`},
+		{"Null", `"Null"`, `"Null"`},
+		{"010", `"010"`, `"010"`},
+		{".5", `".5"`, `".5"`},
+		{"99999999999999999999999", `"99999999999999999999999"`, `"99999999999999999999999"`},
+		{"1e400", `"1e400"`, `"1e400"`},
+		{"-x", `"-x"`, `"-x"`},
+		{"a: b", `"a: b"`, `"a: b"`},
+		{"a #b", `"a #b"`, `"a #b"`},
+		{"a#b", "a#b", "a#b"},
+		{" a", `" a"`, `" a"`},
+		{"a\tb\n", `"a\tb\n"`, `"a\tb\n"`},
+		{"été", "été", "été"},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		w := bufio.NewWriter(&buf)
+		writeYAMLScalar(w, test.s, false)
+		w.WriteByte(' ')
+		writeYAMLScalar(w, test.s, true)
+		w.Flush()
+		assert.Equal(t, buf.String(), test.value+" "+test.key, test.s)
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	ini := New()
+	ini.SetSubsections(true)
+	ini.Set("stale", "1")
+	err := ini.ParseYAML([]byte(`%YAML 1.2
+---
+# comment
+name: goini # inline
+quoted: "a\tb: \u00e9"
+single: 'it''s'
+empty:
+nothing: ~
+hosts: [a.com, "b.com" , 3]
+list:
+- x
+- 'y'
+server:
+  port: 8080
+  "key: quoted": v
+  tls:
+    enabled: true
+    protocols:
+      - TLSv1.2
+      - TLSv1.3
+  timeout: 30s
+url: http://host:80/#top
+remote:
+  a.b:
+    url: git@host
+app: {}
+...
+`))
+	assert.Equal(t, nil, err)
+	tests := []struct {
+		section, key, value string
+		line                int
+	}{
+		{"", "name", "goini", 4},
+		{"", "quoted", "a\tb: é", 5},
+		{"", "single", "it's", 6},
+		{"", "empty", "", 7},
+		{"", "nothing", "", 8},
+		{"", "hosts", "a.com,b.com,3", 9},
+		{"", "list", "x,y", 10},
+		{"server", "port", "8080", 14},
+		{"server", "key: quoted", "v", 15},
+		{"server.tls", "enabled", "true", 17},
+		{"server.tls", "protocols", "TLSv1.2,TLSv1.3", 18},
+		{"server", "timeout", "30s", 21},
+		{"", "url", "http://host:80/#top", 22},
+		{"remote.a.b", "url", "git@host", 25},
+	}
+	for _, test := range tests {
+		v, ok := ini.SectionGet(test.section, test.key)
+		assert.Equal(t, v, test.value, test.key)
+		assert.Equal(t, ok, true, test.key)
+		assert.Equal(t, ini.Line(test.section, test.key), test.line, test.key)
+	}
+	_, ok := ini.Get("stale")
+	assert.Equal(t, ok, false)
+	assert.Equal(t, ini.sectionList(), []string{"", "server", "server.tls", "remote.a.b", "app"})
+	assert.Equal(t, ini.Section(`remote "a.b"`).Name(), `remote "a.b"`)
+}
+
+func TestYAMLRoundTrip(t *testing.T) {
+	ini := parseTestINI(t, convertTestData, parseSubsections)
+	ini.SectionSet("server", "text", "a\tb\n\"c\"\\ #d")
+	ini.SectionSet("server", "yes", "no")
+	c := New()
+	c.SetSubsections(true)
+	err := c.ParseYAML([]byte(yamlString(t, ini)))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, c.Equal(ini), true)
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []string{
+		"a: |\n  text",
+		"a: >\n  text",
+		"a: &x 1",
+		"a: *x",
+		"a: !!str 1",
+		"a: {b: 1}",
+		"a: [1,",
+		"a: [[1]]",
+		"a:\n  - [1]",
+		"a:\n  - b: 1",
+		"- a",
+		"a",
+		"a:b",
+		"a: 'x",
+		`a: "\q"`,
+		"a: b: c",
+		"a:\n\tb: 1",
+		"a:\n    b: 1\n  c: 2",
+		"a: 1\n  b: 2",
+	}
+	for _, test := range tests {
+		err := New().ParseYAML([]byte(test))
+		assert.NotEqual(t, nil, err, test)
+	}
+}